@@ -194,9 +194,18 @@ func TestAPIClientIntegration(t *testing.T) {
 		// Create mock server
 		responses := map[string]interface{}{
 			"/health": map[string]string{"status": "ok"},
-			"/search": map[string]interface{}{
+			"/searches": map[string]interface{}{
 				"status": "ok",
 				"data": map[string]interface{}{
+					"id":     "test-search",
+					"status": "queued",
+				},
+			},
+			"/searches/test-search": map[string]interface{}{
+				"status": "ok",
+				"data": map[string]interface{}{
+					"id":      "test-search",
+					"status":  "done",
 					"zip":     "45140",
 					"radius":  3,
 					"title":   "engineer",
@@ -263,7 +272,7 @@ func TestErrorHandlingIntegration(t *testing.T) {
 	t.Run("ErrorHandling", func(t *testing.T) {
 		responses := map[string]interface{}{
 			"/health": map[string]string{"status": "error", "message": "Service unavailable"},
-			"/search": map[string]string{"status": "error", "message": "Invalid parameters"},
+			"/searches": map[string]string{"status": "error", "message": "Invalid parameters"},
 		}
 
 		server := testutils.MockHTTPServer(t, responses)
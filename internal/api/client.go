@@ -6,6 +6,8 @@ Moving from a monolithic server to a client-server architecture, we need to repl
 package api
 
 import (
+	"bufio"
+	"context"
 	"cliscraper/internal/utils"
 	"net/http"
 	"net/url"
@@ -21,6 +23,8 @@ import (
 type Client struct {
 	BaseURL string
 	HTTPClient *http.Client
+	// how often Search polls a running search
+	PollInterval time.Duration
 }
 
 type Response struct {
@@ -33,8 +37,9 @@ func NewClient(baseURL string) *Client {
 	return &Client{
 		BaseURL: baseURL,
 		HTTPClient: &http.Client{
-		Timeout: 1800 * time.Second, // outrageous timeout for scraping, just dont want to deal with issues with tomeouts right now
+		Timeout: 30 * time.Second, // searches run in the background now, requests should come back quickly
 	},
+		PollInterval: time.Second,
 	}
 }
 
@@ -49,18 +54,155 @@ func (c *Client) Health() error {
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("health check failed: %s", resp.Status)
 	}
+
+	// a proxy or half-started backend can answer 200 with an error body
+	var apiResp Response
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err == nil && apiResp.Status != "ok" {
+		return fmt.Errorf("health check failed: %s", apiResp.Message)
+	}
 	return nil
 }
 
+// start a search and poll it until it finishes
 func (c *Client) Search(zip, radius, title string) ([]utils.JobPageResult, error) {
+	id, err := c.StartSearch(zip, radius, title)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		status, err := c.SearchStatus(id)
+		if err != nil {
+			return nil, err
+		}
+
+		switch status.Status {
+		case utils.SearchDone:
+			if status.Results == nil {
+				return []utils.JobPageResult{}, nil
+			}
+			return status.Results, nil
+		case utils.SearchFailed:
+			msg := status.Error
+			if msg == "" {
+				msg = "unknown backend error"
+			}
+			return nil, fmt.Errorf("search failed: %s", msg)
+		case utils.SearchCancelled:
+			return nil, fmt.Errorf("search %s was cancelled", id)
+		}
+
+		time.Sleep(c.PollInterval)
+	}
+}
+
+// enqueue a search on the backend and return its id
+func (c *Client) StartSearch(zip, radius, title string) (string, error) {
 	params := url.Values{}
 	params.Set("zip", zip)
 	params.Set("radius", radius)
 	params.Set("title", title)
 
-	url := fmt.Sprintf("%s/search?%s", c.BaseURL, params.Encode())
+	status, err := c.doSearchRequest(http.MethodPost, c.BaseURL+"/searches", strings.NewReader(params.Encode()))
+	if err != nil {
+		return "", err
+	}
+	if status.ID == "" {
+		return "", fmt.Errorf("backend did not return a search id")
+	}
+	return status.ID, nil
+}
 
-	resp, err := c.HTTPClient.Get(url)
+// current status, counts and partial results of a search
+func (c *Client) SearchStatus(id string) (*utils.SearchStatus, error) {
+	return c.doSearchRequest(http.MethodGet, c.BaseURL+"/searches/"+url.PathEscape(id), nil)
+}
+
+// ask the backend to stop a queued or running search
+func (c *Client) CancelSearch(id string) error {
+	_, err := c.doSearchRequest(http.MethodDelete, c.BaseURL+"/searches/"+url.PathEscape(id), nil)
+	return err
+}
+
+// start a new search over the sites that failed in a finished one, returns the new search's id
+func (c *Client) RetrySearch(id string) (string, error) {
+	status, err := c.doSearchRequest(http.MethodPost, c.BaseURL+"/searches/"+url.PathEscape(id)+"/retry", nil)
+	if err != nil {
+		return "", err
+	}
+	return status.ID, nil
+}
+
+// follow the event stream of a search, calling fn for each event until the search completes, ctx is done or fn errors
+func (c *Client) StreamSearch(ctx context.Context, id string, fn func(utils.SearchEvent) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/searches/"+url.PathEscape(id)+"/events", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	// the stream lives as long as the search, so the regular client timeout can't apply
+	stream := &http.Client{Transport: c.HTTPClient.Transport}
+	resp, err := stream.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to open event stream: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("backend returned status %d: %s", resp.StatusCode, truncate(body, 200))
+	}
+
+	// minimal SSE reader, we only care about data lines, events end on a blank line
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data.Len() == 0 {
+				continue
+			}
+			var ev utils.SearchEvent
+			if err := json.Unmarshal([]byte(data.String()), &ev); err != nil {
+				return fmt.Errorf("invalid event from API: %w", err)
+			}
+			data.Reset()
+
+			if err := fn(ev); err != nil {
+				return err
+			}
+			if ev.Type == utils.EventComplete {
+				return nil
+			}
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("event stream interrupted: %w", err)
+	}
+	return fmt.Errorf("event stream closed before search %s completed", id)
+}
+
+func (c *Client) doSearchRequest(method, url string, form io.Reader) (*utils.SearchStatus, error) {
+	req, err := http.NewRequest(method, url, form)
+	if err != nil {
+		return nil, err
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call API: %w", err)
 	}
@@ -71,57 +213,39 @@ func (c *Client) Search(zip, radius, title string) ([]utils.JobPageResult, error
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	// handle Overpass HTML responses early
+	// handle proxy/HTML error pages early
 	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("<")) {
-		return nil, fmt.Errorf("backend returned HTML instead of JSON (likely Overpass error or rate limit)\nURL: %s\nBody: %s", url, string(body[:120]))
+		return nil, fmt.Errorf("backend returned HTML instead of JSON\nURL: %s\nBody: %s", url, truncate(body, 120))
 	}
 
-	// handle non-200 statuses
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("backend returned status %d: %s", resp.StatusCode, string(body))
-	}
-
-	// decode the unified response
 	var apiResp Response
 	if err := json.Unmarshal(body, &apiResp); err != nil {
-		return nil, fmt.Errorf("invalid JSON from API: %w\nBody:\n%s", err, string(body[:200]))
+		return nil, fmt.Errorf("invalid JSON from API (status %d): %w\nBody:\n%s", resp.StatusCode, err, truncate(body, 200))
 	}
 
-	// handle no results cleanly
-	if apiResp.Status != "ok" {
+	if resp.StatusCode >= http.StatusBadRequest || apiResp.Status != "ok" {
 		msg := apiResp.Message
-		if strings.Contains(msg, "must provide at least one element") || strings.Contains(msg, "no businesses found") {
-			// treat as valid empty result
-			return []utils.JobPageResult{}, nil
-		}
 		if msg == "" {
-			msg = "unknown backend error"
+			msg = resp.Status
 		}
-		return nil, fmt.Errorf("search failed: %s", msg)
-	}
-
-	// gracefully handle missing or empty data
-	if len(apiResp.Data) == 0 {
-		return []utils.JobPageResult{}, nil
-	}
-
-	var payload struct {
-		Zip     string               `json:"zip"`
-		Radius  int                  `json:"radius"`
-		Title   string               `json:"title"`
-		Results []utils.JobPageResult `json:"results"`
+		if resp.StatusCode == http.StatusBadRequest {
+			return nil, fmt.Errorf("%w: %s", utils.ErrRejected, msg)
+		}
+		return nil, fmt.Errorf("backend returned status %d: %s", resp.StatusCode, msg)
 	}
 
-	if err := json.Unmarshal(apiResp.Data, &payload); err != nil {
-		return nil, fmt.Errorf("failed to decode payload: %w\nData:\n%s", err, string(apiResp.Data))
+	var status utils.SearchStatus
+	if err := json.Unmarshal(apiResp.Data, &status); err != nil {
+		return nil, fmt.Errorf("failed to decode search: %w\nData:\n%s", err, string(apiResp.Data))
 	}
+	return &status, nil
+}
 
-	// return empty but valid slice if nothing found
-	if len(payload.Results) == 0 {
-		return []utils.JobPageResult{}, nil
+func truncate(b []byte, n int) string {
+	if len(b) > n {
+		return string(b[:n])
 	}
-
-	return payload.Results, nil
+	return string(b)
 }
 
 func (c *Client) Results() ([]utils.JobPageResult, error) {
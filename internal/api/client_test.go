@@ -1,13 +1,17 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"cliscraper/internal/testutils"
+	"cliscraper/internal/utils"
 )
 
 func TestNewClient(t *testing.T) {
@@ -22,8 +26,12 @@ func TestNewClient(t *testing.T) {
 		t.Error("Expected HTTP client to be initialized")
 	}
 	
-	if client.HTTPClient.Timeout != 1800*time.Second {
-		t.Errorf("Expected timeout 1800s, got %v", client.HTTPClient.Timeout)
+	if client.HTTPClient.Timeout != 30*time.Second {
+		t.Errorf("Expected timeout 30s, got %v", client.HTTPClient.Timeout)
+	}
+
+	if client.PollInterval != time.Second {
+		t.Errorf("Expected poll interval 1s, got %v", client.PollInterval)
 	}
 }
 
@@ -63,53 +71,66 @@ func TestClientHealthFailure(t *testing.T) {
 }
 
 func TestClientSearch(t *testing.T) {
-	// Create mock server
+	// Create mock server that finishes the search on the second poll
 	expectedResults := testutils.MockJobResults()
+	polls := 0
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path != "/search" {
-			t.Errorf("Expected path /search, got %s", r.URL.Path)
-		}
-		
-		// Check query parameters
-		zip := r.URL.Query().Get("zip")
-		radius := r.URL.Query().Get("radius")
-		title := r.URL.Query().Get("title")
-		
-		if zip != "10001" {
-			t.Errorf("Expected zip 10001, got %s", zip)
-		}
-		if radius != "5" {
-			t.Errorf("Expected radius 5, got %s", radius)
-		}
-		if title != "engineer" {
-			t.Errorf("Expected title engineer, got %s", title)
-		}
-		
-		response := Response{
-			Status: "ok",
-			Data: json.RawMessage(`{
-				"zip": "10001",
-				"radius": 5,
-				"title": "engineer",
-				"results": [
-					{"business_name": "Test Company 1", "url": "https://example1.com/careers", "description": "Software engineering positions"},
-					{"business_name": "Test Company 2", "url": "https://example2.com/jobs", "description": "Developer roles"}
-				]
-			}`),
-		}
-		
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/searches":
+			// Check form parameters
+			if zip := r.FormValue("zip"); zip != "10001" {
+				t.Errorf("Expected zip 10001, got %s", zip)
+			}
+			if radius := r.FormValue("radius"); radius != "5" {
+				t.Errorf("Expected radius 5, got %s", radius)
+			}
+			if title := r.FormValue("title"); title != "engineer" {
+				t.Errorf("Expected title engineer, got %s", title)
+			}
+
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(Response{
+				Status: "ok",
+				Data:   json.RawMessage(`{"id": "search-1", "status": "queued"}`),
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/searches/search-1":
+			polls++
+			data := `{"id": "search-1", "status": "running", "counts": {"scanned": 1}}`
+			if polls > 1 {
+				data = `{
+					"id": "search-1",
+					"status": "done",
+					"zip": "10001",
+					"radius": 5,
+					"title": "engineer",
+					"results": [
+						{"business_name": "Test Company 1", "url": "https://example1.com/careers", "description": "Software engineering positions"},
+						{"business_name": "Test Company 2", "url": "https://example2.com/jobs", "description": "Developer roles"}
+					]
+				}`
+			}
+			json.NewEncoder(w).Encode(Response{Status: "ok", Data: json.RawMessage(data)})
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
 	}))
 	defer server.Close()
 	
 	client := NewClient(server.URL)
+	client.PollInterval = time.Millisecond
 	
 	results, err := client.Search("10001", "5", "engineer")
 	if err != nil {
 		t.Fatalf("Search failed: %v", err)
 	}
 	
+	if polls != 2 {
+		t.Errorf("Expected 2 status polls, got %d", polls)
+	}
+
 	if len(results) != len(expectedResults) {
 		t.Fatalf("Expected %d results, got %d", len(expectedResults), len(results))
 	}
@@ -124,6 +145,95 @@ func TestClientSearch(t *testing.T) {
 	}
 }
 
+func TestClientSearchFailed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data := `{"id": "search-1", "status": "queued"}`
+		if r.Method == http.MethodGet {
+			data = `{"id": "search-1", "status": "failed", "error": "failed to locate businesses"}`
+		}
+		json.NewEncoder(w).Encode(Response{Status: "ok", Data: json.RawMessage(data)})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.PollInterval = time.Millisecond
+
+	_, err := client.Search("10001", "5", "engineer")
+	if err == nil || !strings.Contains(err.Error(), "failed to locate businesses") {
+		t.Errorf("Expected search failure to be reported, got %v", err)
+	}
+}
+
+func TestClientCancelSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete || r.URL.Path != "/searches/search-1" {
+			t.Errorf("Expected DELETE /searches/search-1, got %s %s", r.Method, r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(Response{
+			Status: "ok",
+			Data:   json.RawMessage(`{"id": "search-1", "status": "cancelled"}`),
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	if err := client.CancelSearch("search-1"); err != nil {
+		t.Errorf("CancelSearch failed: %v", err)
+	}
+}
+
+func TestClientStartSearchRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(Response{Status: "error", Message: "invalid title query: unterminated quote"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	_, err := client.StartSearch("45140", "5", `"line cook`)
+	if !errors.Is(err, utils.ErrRejected) || !strings.Contains(err.Error(), "unterminated quote") {
+		t.Errorf("Expected a rejection carrying the server's message, got %v", err)
+	}
+}
+
+func TestClientRetrySearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/searches/search-1/retry" {
+			t.Errorf("Expected POST /searches/search-1/retry, got %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(Response{
+			Status: "ok",
+			Data:   json.RawMessage(`{"id": "search-2", "status": "queued", "retry_of": "search-1"}`),
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	id, err := client.RetrySearch("search-1")
+	if err != nil || id != "search-2" {
+		t.Errorf("Expected the retry's id, got %q, %v", id, err)
+	}
+}
+
+func TestClientSearchStatusNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(Response{Status: "error", Message: "search not found"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	_, err := client.SearchStatus("missing")
+	if err == nil || !strings.Contains(err.Error(), "search not found") {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
+
 func TestClientSearchError(t *testing.T) {
 	// Create mock server that returns error
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -232,4 +342,51 @@ func TestResponseStruct(t *testing.T) {
 	if string(response.Data) != `{"key": "value"}` {
 		t.Errorf("Expected data '{\"key\": \"value\"}', got %s", string(response.Data))
 	}
-}
\ No newline at end of file
+}
+func TestClientStreamSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/searches/search-1/events" {
+			t.Errorf("Expected path /searches/search-1/events, got %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(": keep-alive\n\n"))
+		w.Write([]byte("id: 1\nevent: scraped\ndata: {\"seq\":1,\"type\":\"scraped\",\"counts\":{\"sites\":2,\"scanned\":1}}\n\n"))
+		w.Write([]byte("id: 2\nevent: complete\ndata: {\"seq\":2,\"type\":\"complete\",\"status\":\"done\"}\n\n"))
+		w.Write([]byte("id: 3\nevent: scraped\ndata: {\"seq\":3,\"type\":\"scraped\"}\n\n"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	var types []string
+	err := client.StreamSearch(context.Background(), "search-1", func(ev utils.SearchEvent) error {
+		types = append(types, ev.Type)
+		if ev.Seq == 1 && ev.Counts.Scanned != 1 {
+			t.Errorf("Expected scanned count 1, got %d", ev.Counts.Scanned)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamSearch failed: %v", err)
+	}
+
+	// stream stops at the completion event
+	if len(types) != 2 || types[1] != utils.EventComplete {
+		t.Errorf("Expected [scraped complete], got %v", types)
+	}
+}
+
+func TestClientStreamSearchClosedEarly(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("id: 1\nevent: scraped\ndata: {\"seq\":1,\"type\":\"scraped\"}\n\n"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	err := client.StreamSearch(context.Background(), "search-1", func(utils.SearchEvent) error { return nil })
+	if err == nil {
+		t.Error("Expected error when stream ends before completion")
+	}
+}
@@ -0,0 +1,77 @@
+package ats
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"cliscraper/internal/database"
+)
+
+// ashby public job posting api, https://developers.ashbyhq.com/docs/public-job-posting-api
+type AshbyAdapter struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func NewAshbyAdapter() *AshbyAdapter {
+	return &AshbyAdapter{
+		BaseURL:    "https://api.ashbyhq.com",
+		HTTPClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type ashbyResponse struct {
+	Jobs []struct {
+		Title            string `json:"title"`
+		Location         string `json:"location"`
+		JobURL           string `json:"jobUrl"`
+		DescriptionPlain string `json:"descriptionPlain"`
+		DescriptionHTML  string `json:"descriptionHtml"`
+		PublishedAt      string `json:"publishedAt"`
+		// unlisted postings are reachable by link only, the company does not advertise them
+		IsListed *bool `json:"isListed"`
+	} `json:"jobs"`
+}
+
+func (a *AshbyAdapter) Vendor() string { return Ashby }
+
+func (a *AshbyAdapter) BoardURL(board string) string {
+	return "https://jobs.ashbyhq.com/" + url.PathEscape(board)
+}
+
+func (a *AshbyAdapter) APIURL(board string) string {
+	return fmt.Sprintf("%s/posting-api/job-board/%s", strings.TrimRight(a.BaseURL, "/"), url.PathEscape(board))
+}
+
+func (a *AshbyAdapter) Postings(ctx context.Context, board string) ([]database.Job, error) {
+	var data ashbyResponse
+	if err := getJSON(ctx, a.HTTPClient, a.APIURL(board), &data); err != nil {
+		return nil, fmt.Errorf("ashby board %s: %w", board, err)
+	}
+
+	jobs := make([]database.Job, 0, len(data.Jobs))
+	for _, p := range data.Jobs {
+		if p.IsListed != nil && !*p.IsListed {
+			continue
+		}
+		desc := p.DescriptionPlain
+		if desc == "" {
+			desc = PlainText(p.DescriptionHTML)
+		}
+		published, _ := time.Parse(time.RFC3339, p.PublishedAt)
+		jobs = append(jobs, database.Job{
+			Title:       strings.TrimSpace(p.Title),
+			Location:    p.Location,
+			URL:         p.JobURL,
+			Description: strings.TrimSpace(desc),
+			ATSVendor:   Ashby,
+			ATSBoard:    board,
+			PostedAt:    timePtr(published),
+		})
+	}
+	return jobs, nil
+}
@@ -0,0 +1,142 @@
+// adapters for the public job board apis of applicant tracking systems. given the board a company uses, they return its open postings as database.Job values.
+package ats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+
+	"cliscraper/internal/database"
+)
+
+// vendors with an adapter, same names the scraper's ATS detection reports
+const (
+	Greenhouse = "greenhouse"
+	Lever      = "lever"
+	Ashby      = "ashby"
+)
+
+var ErrBoardNotFound = errors.New("job board not found")
+
+// board api responses past this size are cut off
+const maxResponseSize = 10 << 20
+
+type Adapter interface {
+	Vendor() string
+	// open postings on the board, Title/Location/URL/Description filled in, BusinessID left for the caller
+	Postings(ctx context.Context, board string) ([]database.Job, error)
+	// public page listing the board's postings, used as the job page when only the api confirmed openings
+	BoardURL(board string) string
+	// api url requested for the board, so callers can rate limit on its host
+	APIURL(board string) string
+}
+
+// adapters keyed by vendor
+type Adapters map[string]Adapter
+
+// base urls for the board apis, empty fields use the public endpoints
+type Config struct {
+	GreenhouseURL string
+	LeverURL      string
+	AshbyURL      string
+	HTTPClient    *http.Client
+}
+
+func NewAdapters(cfg Config) Adapters {
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+
+	gh, lv, ab := NewGreenhouseAdapter(), NewLeverAdapter(), NewAshbyAdapter()
+	gh.HTTPClient, lv.HTTPClient, ab.HTTPClient = client, client, client
+	if cfg.GreenhouseURL != "" {
+		gh.BaseURL = cfg.GreenhouseURL
+	}
+	if cfg.LeverURL != "" {
+		lv.BaseURL = cfg.LeverURL
+	}
+	if cfg.AshbyURL != "" {
+		ab.BaseURL = cfg.AshbyURL
+	}
+	return Adapters{Greenhouse: gh, Lever: lv, Ashby: ab}
+}
+
+// adapters with base urls from ATS_GREENHOUSE_URL, ATS_LEVER_URL and ATS_ASHBY_URL
+func NewAdaptersFromEnv() Adapters {
+	return NewAdapters(Config{
+		GreenhouseURL: os.Getenv("ATS_GREENHOUSE_URL"),
+		LeverURL:      os.Getenv("ATS_LEVER_URL"),
+		AshbyURL:      os.Getenv("ATS_ASHBY_URL"),
+	})
+}
+
+// GET a board api url and decode the json into v
+func getJSON(ctx context.Context, client *http.Client, apiURL string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrBoardNotFound, apiURL)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, apiURL)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(v); err != nil {
+		return fmt.Errorf("JSON unmarshal failed: %w", err)
+	}
+	return nil
+}
+
+// strip markup from a posting description, boards hand out html (greenhouse even escapes it twice)
+func PlainText(s string) string {
+	if !strings.Contains(s, "<") && strings.Contains(s, "&lt;") {
+		s = html.UnescapeString(s)
+	}
+	doc, err := html.Parse(strings.NewReader(s))
+	if err != nil {
+		return strings.TrimSpace(s)
+	}
+
+	var b strings.Builder
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.Type == html.ElementNode && (n.Data == "script" || n.Data == "style") {
+			return
+		}
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			b.WriteString(" ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(doc)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,101 @@
+package ats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"cliscraper/internal/database"
+)
+
+// stand-in for the three board apis
+func newBoardServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/boards/acme/jobs", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("content") != "true" {
+			t.Errorf("Expected content=true, got %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"jobs":[{"id":1,"title":"Line Cook","absolute_url":"https://boards.greenhouse.io/acme/jobs/1",
+			"updated_at":"2024-05-01T10:00:00-04:00","location":{"name":"Cincinnati, OH"},
+			"content":"&lt;p&gt;Cook &amp;amp; prep&lt;/p&gt;"}]}`)
+	})
+	mux.HandleFunc("/v0/postings/acme", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id":"abc","text":"Sous Chef","hostedUrl":"https://jobs.lever.co/acme/abc",
+			"descriptionPlain":"Run the line","createdAt":1714557600000,"categories":{"location":"Mason, OH"}}]`)
+	})
+	mux.HandleFunc("/posting-api/job-board/acme", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"jobs":[
+			{"title":"Dishwasher","location":"Remote","jobUrl":"https://jobs.ashbyhq.com/acme/1","descriptionHtml":"<p>Wash <b>dishes</b></p>","publishedAt":"2024-05-01T00:00:00Z","isListed":true},
+			{"title":"Secret Role","location":"Remote","jobUrl":"https://jobs.ashbyhq.com/acme/2","isListed":false}]}`)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestAdapters(srv *httptest.Server) Adapters {
+	return NewAdapters(Config{
+		GreenhouseURL: srv.URL,
+		LeverURL:      srv.URL,
+		AshbyURL:      srv.URL,
+		HTTPClient:    srv.Client(),
+	})
+}
+
+func TestAdaptersPostings(t *testing.T) {
+	adapters := newTestAdapters(newBoardServer(t))
+
+	tests := []struct {
+		vendor   string
+		expected database.Job
+	}{
+		{
+			vendor: Greenhouse,
+			expected: database.Job{Title: "Line Cook", Location: "Cincinnati, OH", URL: "https://boards.greenhouse.io/acme/jobs/1",
+				Description: "Cook & prep", ATSVendor: Greenhouse, ATSBoard: "acme"},
+		},
+		{
+			vendor: Lever,
+			expected: database.Job{Title: "Sous Chef", Location: "Mason, OH", URL: "https://jobs.lever.co/acme/abc",
+				Description: "Run the line", ATSVendor: Lever, ATSBoard: "acme"},
+		},
+		{
+			vendor: Ashby,
+			expected: database.Job{Title: "Dishwasher", Location: "Remote", URL: "https://jobs.ashbyhq.com/acme/1",
+				Description: "Wash dishes", ATSVendor: Ashby, ATSBoard: "acme"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.vendor, func(t *testing.T) {
+			jobs, err := adapters[tt.vendor].Postings(context.Background(), "acme")
+			if err != nil {
+				t.Fatalf("Postings: %v", err)
+			}
+			// unlisted ashby postings are dropped
+			if len(jobs) != 1 {
+				t.Fatalf("Expected 1 posting, got %d", len(jobs))
+			}
+			job := jobs[0]
+			if job.PostedAt == nil {
+				t.Error("Expected posting date to be set")
+			}
+			job.PostedAt = nil
+			if !reflect.DeepEqual(job, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, job)
+			}
+		})
+	}
+}
+
+func TestAdaptersErrors(t *testing.T) {
+	adapters := newTestAdapters(newBoardServer(t))
+
+	if _, err := adapters[Greenhouse].Postings(context.Background(), "missing"); !errors.Is(err, ErrBoardNotFound) {
+		t.Errorf("Expected ErrBoardNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package ats
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"cliscraper/internal/database"
+)
+
+// greenhouse job board api, https://developers.greenhouse.io/job-board.html
+type GreenhouseAdapter struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func NewGreenhouseAdapter() *GreenhouseAdapter {
+	return &GreenhouseAdapter{
+		BaseURL:    "https://boards-api.greenhouse.io",
+		HTTPClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type greenhouseResponse struct {
+	Jobs []struct {
+		ID          int64  `json:"id"`
+		Title       string `json:"title"`
+		AbsoluteURL string `json:"absolute_url"`
+		UpdatedAt   string `json:"updated_at"`
+		Content     string `json:"content"`
+		Location    struct {
+			Name string `json:"name"`
+		} `json:"location"`
+	} `json:"jobs"`
+}
+
+func (a *GreenhouseAdapter) Vendor() string { return Greenhouse }
+
+func (a *GreenhouseAdapter) BoardURL(board string) string {
+	return "https://boards.greenhouse.io/" + url.PathEscape(board)
+}
+
+func (a *GreenhouseAdapter) APIURL(board string) string {
+	return fmt.Sprintf("%s/v1/boards/%s/jobs?content=true", strings.TrimRight(a.BaseURL, "/"), url.PathEscape(board))
+}
+
+func (a *GreenhouseAdapter) Postings(ctx context.Context, board string) ([]database.Job, error) {
+	var data greenhouseResponse
+	if err := getJSON(ctx, a.HTTPClient, a.APIURL(board), &data); err != nil {
+		return nil, fmt.Errorf("greenhouse board %s: %w", board, err)
+	}
+
+	jobs := make([]database.Job, 0, len(data.Jobs))
+	for _, p := range data.Jobs {
+		updated, _ := time.Parse(time.RFC3339, p.UpdatedAt)
+		jobs = append(jobs, database.Job{
+			Title:       strings.TrimSpace(p.Title),
+			Location:    p.Location.Name,
+			URL:         p.AbsoluteURL,
+			Description: PlainText(p.Content),
+			ATSVendor:   Greenhouse,
+			ATSBoard:    board,
+			PostedAt:    timePtr(updated),
+		})
+	}
+	return jobs, nil
+}
@@ -0,0 +1,79 @@
+package ats
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"cliscraper/internal/database"
+)
+
+// lever postings api, https://github.com/lever/postings-api
+type LeverAdapter struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func NewLeverAdapter() *LeverAdapter {
+	return &LeverAdapter{
+		BaseURL:    "https://api.lever.co",
+		HTTPClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type leverPosting struct {
+	ID               string `json:"id"`
+	Text             string `json:"text"`
+	HostedURL        string `json:"hostedUrl"`
+	DescriptionPlain string `json:"descriptionPlain"`
+	Description      string `json:"description"`
+	// milliseconds since epoch
+	CreatedAt  int64 `json:"createdAt"`
+	Categories struct {
+		Location   string `json:"location"`
+		Commitment string `json:"commitment"`
+		Team       string `json:"team"`
+	} `json:"categories"`
+}
+
+func (a *LeverAdapter) Vendor() string { return Lever }
+
+func (a *LeverAdapter) BoardURL(board string) string {
+	return "https://jobs.lever.co/" + url.PathEscape(board)
+}
+
+func (a *LeverAdapter) APIURL(board string) string {
+	return fmt.Sprintf("%s/v0/postings/%s?mode=json", strings.TrimRight(a.BaseURL, "/"), url.PathEscape(board))
+}
+
+func (a *LeverAdapter) Postings(ctx context.Context, board string) ([]database.Job, error) {
+	var data []leverPosting
+	if err := getJSON(ctx, a.HTTPClient, a.APIURL(board), &data); err != nil {
+		return nil, fmt.Errorf("lever site %s: %w", board, err)
+	}
+
+	jobs := make([]database.Job, 0, len(data))
+	for _, p := range data {
+		desc := p.DescriptionPlain
+		if desc == "" {
+			desc = PlainText(p.Description)
+		}
+		var created time.Time
+		if p.CreatedAt > 0 {
+			created = time.UnixMilli(p.CreatedAt).UTC()
+		}
+		jobs = append(jobs, database.Job{
+			Title:       strings.TrimSpace(p.Text),
+			Location:    p.Categories.Location,
+			URL:         p.HostedURL,
+			Description: strings.TrimSpace(desc),
+			ATSVendor:   Lever,
+			ATSBoard:    board,
+			PostedAt:    timePtr(created),
+		})
+	}
+	return jobs, nil
+}
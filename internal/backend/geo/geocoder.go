@@ -0,0 +1,229 @@
+// resolves zip codes to coordinates, either from the bundled centroid dataset or a remote api
+package geo
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// anything that can turn a zip into a lat/lon pair
+type Geocoder interface {
+	Geocode(zip string) (float64, float64, error)
+}
+
+var ErrZipNotFound = errors.New("zip code not found")
+
+// backend names accepted by NewGeocoder, chained with commas e.g. "offline,zippopotam"
+const (
+	BackendOffline    = "offline"
+	BackendZippopotam = "zippopotam"
+
+	DefaultGeocoderBackends = BackendOffline + "," + BackendZippopotam
+)
+
+// zip,lat,lon centroids bundled into the binary so lookups work without network access.
+// regenerate the full dataset from the census gazetteer with `make geo-data`
+//
+//go:embed data/zip_centroids.csv
+var zipCentroidsCSV string
+
+type centroid struct {
+	Lat float64
+	Lon float64
+}
+
+// geocoder backed by an in-memory zip -> centroid table
+type OfflineGeocoder struct {
+	centroids map[string]centroid
+}
+
+var (
+	bundledOnce sync.Once
+	bundled     *OfflineGeocoder
+	bundledErr  error
+)
+
+// returns the geocoder for the embedded dataset, parsed once and shared
+func NewOfflineGeocoder() (*OfflineGeocoder, error) {
+	bundledOnce.Do(func() {
+		bundled, bundledErr = LoadOfflineGeocoder(strings.NewReader(zipCentroidsCSV))
+	})
+	return bundled, bundledErr
+}
+
+// parse a zip,lat,lon csv, the header row is optional
+func LoadOfflineGeocoder(r io.Reader) (*OfflineGeocoder, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	g := &OfflineGeocoder{centroids: make(map[string]centroid)}
+	for line := 1; ; line++ {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading zip dataset: %w", err)
+		}
+		if line == 1 && strings.EqualFold(rec[0], "zip") {
+			continue
+		}
+
+		lat, err1 := strconv.ParseFloat(rec[1], 64)
+		lon, err2 := strconv.ParseFloat(rec[2], 64)
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("invalid coordinates for zip %s on line %d", rec[0], line)
+		}
+		g.centroids[rec[0]] = centroid{Lat: lat, Lon: lon}
+	}
+	return g, nil
+}
+
+func (g *OfflineGeocoder) Geocode(zip string) (float64, float64, error) {
+	c, ok := g.centroids[strings.TrimSpace(zip)]
+	if !ok {
+		return 0, 0, fmt.Errorf("%w: %s", ErrZipNotFound, zip)
+	}
+	return c.Lat, c.Lon, nil
+}
+
+// number of zips in the table
+func (g *OfflineGeocoder) Len() int {
+	return len(g.centroids)
+}
+
+// the census gazetteer lists about 33k ZCTAs, a table much smaller than this is a sample
+const fullDatasetMin = 30000
+
+// whether the table covers the country, false for a sample that leaves most lookups to the next backend
+func (g *OfflineGeocoder) Complete() bool {
+	return g.Len() >= fullDatasetMin
+}
+
+// geocoder backed by api.zippopotam.us, BaseURL can be pointed at a stand-in server for tests
+type ZippopotamGeocoder struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func NewZippopotamGeocoder() *ZippopotamGeocoder {
+	return &ZippopotamGeocoder{
+		BaseURL:    "https://api.zippopotam.us/us",
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// zippopotamus api allows us to extract coordinate data from a zip code. connect to the api via net/http, parse lat/lgn data from the response, and return it
+func (g *ZippopotamGeocoder) Geocode(zip string) (float64, float64, error) {
+	zpURL := fmt.Sprintf("%s/%s", strings.TrimRight(g.BaseURL, "/"), zip)
+	resp, err := g.HTTPClient.Get(zpURL)
+	if err != nil {
+		return 0, 0, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return 0, 0, fmt.Errorf("%w: %s", ErrZipNotFound, zip)
+	}
+	// rate limited or down, there is no body worth decoding
+	if resp.StatusCode != http.StatusOK {
+		return 0, 0, fmt.Errorf("zippopotam returned %s for zip %s", resp.Status, zip)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, 0, fmt.Errorf("reading response failed: %w", err)
+	}
+
+	var data ZippoResponse
+	if err := json.Unmarshal(body, &data); err != nil {
+		return 0, 0, fmt.Errorf("JSON unmarshal failed: %w", err)
+	}
+
+	if len(data.Places) == 0 {
+		return 0, 0, fmt.Errorf("no places found for zip %s", zip)
+	}
+
+	place := data.Places[0]
+	lat, err1 := strconv.ParseFloat(place.Latitude, 64)
+	lon, err2 := strconv.ParseFloat(place.Longitude, 64)
+	if err1 != nil || err2 != nil {
+		return 0, 0, fmt.Errorf("invalid coordinates in API response")
+	}
+
+	return lat, lon, nil
+}
+
+// tries each geocoder in order and returns the first hit
+type ChainGeocoder []Geocoder
+
+func (c ChainGeocoder) Geocode(zip string) (float64, float64, error) {
+	var errs []error
+	for _, g := range c {
+		lat, lon, err := g.Geocode(zip)
+		if err == nil {
+			return lat, lon, nil
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return 0, 0, fmt.Errorf("no geocoder configured")
+	}
+	return 0, 0, errors.Join(errs...)
+}
+
+// build a geocoder from a comma separated list of backends, tried in the order given
+func NewGeocoder(backends string) (Geocoder, error) {
+	if strings.TrimSpace(backends) == "" {
+		backends = DefaultGeocoderBackends
+	}
+
+	var chain ChainGeocoder
+	for _, name := range strings.Split(backends, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case BackendOffline:
+			g, err := NewOfflineGeocoder()
+			if err != nil {
+				return nil, err
+			}
+			chain = append(chain, g)
+		case BackendZippopotam:
+			chain = append(chain, NewZippopotamGeocoder())
+		case "":
+			continue
+		default:
+			return nil, fmt.Errorf("unknown geocoder backend %q", name)
+		}
+	}
+
+	if len(chain) == 1 {
+		return chain[0], nil
+	}
+	return chain, nil
+}
+
+// geocoder selected by the GEOCODER env var, defaults to the bundled dataset with zippopotam as fallback
+func NewGeocoderFromEnv() (Geocoder, error) {
+	return NewGeocoder(os.Getenv("GEOCODER"))
+}
+
+// default chain, used when nothing has been configured
+func DefaultGeocoder() Geocoder {
+	g, err := NewGeocoder(DefaultGeocoderBackends)
+	if err != nil {
+		// the bundled dataset is broken, still let the remote api do its job
+		return NewZippopotamGeocoder()
+	}
+	return g
+}
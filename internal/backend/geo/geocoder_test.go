@@ -0,0 +1,191 @@
+package geo
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOfflineGeocoderBundledDataset(t *testing.T) {
+	g, err := NewOfflineGeocoder()
+	if err != nil {
+		t.Fatalf("Failed to load bundled dataset: %v", err)
+	}
+
+	if g.Len() == 0 {
+		t.Fatal("Expected bundled dataset to contain zip codes")
+	}
+
+	lat, lon, err := g.Geocode("45140")
+	if err != nil {
+		t.Fatalf("Geocode(45140) failed: %v", err)
+	}
+
+	if lat != 39.2689 || lon != -84.2638 {
+		t.Errorf("Expected 39.2689,-84.2638 for 45140, got %f,%f", lat, lon)
+	}
+}
+
+func TestOfflineGeocoderComplete(t *testing.T) {
+	g, err := LoadOfflineGeocoder(strings.NewReader("zip,lat,lon\n45140,39.2689,-84.2638\n"))
+	if err != nil {
+		t.Fatalf("LoadOfflineGeocoder: %v", err)
+	}
+	if g.Complete() {
+		t.Error("Expected a one-zip table to count as a sample")
+	}
+}
+
+func TestOfflineGeocoderUnknownZip(t *testing.T) {
+	g, err := LoadOfflineGeocoder(strings.NewReader("zip,lat,lon\n45140,39.2689,-84.2638\n"))
+	if err != nil {
+		t.Fatalf("LoadOfflineGeocoder failed: %v", err)
+	}
+
+	_, _, err = g.Geocode("99999")
+	if !errors.Is(err, ErrZipNotFound) {
+		t.Errorf("Expected ErrZipNotFound, got %v", err)
+	}
+}
+
+func TestLoadOfflineGeocoderInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "Bad latitude", data: "45140,north,-84.2638\n"},
+		{name: "Missing column", data: "45140,39.2689\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadOfflineGeocoder(strings.NewReader(tt.data)); err == nil {
+				t.Errorf("Expected error loading %q", tt.data)
+			}
+		})
+	}
+}
+
+func TestZippopotamGeocoder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/us/10001" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte("slow down"))
+			return
+		}
+		if r.URL.Path != "/us/45140" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"post code":"45140","places":[{"place name":"Loveland","longitude":"-84.2638","latitude":"39.2689"}]}`))
+	}))
+	defer server.Close()
+
+	g := NewZippopotamGeocoder()
+	g.BaseURL = server.URL + "/us"
+
+	lat, lon, err := g.Geocode("45140")
+	if err != nil {
+		t.Fatalf("Geocode failed: %v", err)
+	}
+	if lat != 39.2689 || lon != -84.2638 {
+		t.Errorf("Expected 39.2689,-84.2638, got %f,%f", lat, lon)
+	}
+
+	_, _, err = g.Geocode("00000")
+	if !errors.Is(err, ErrZipNotFound) {
+		t.Errorf("Expected ErrZipNotFound for unknown zip, got %v", err)
+	}
+
+	_, _, err = g.Geocode("10001")
+	if err == nil || errors.Is(err, ErrZipNotFound) || !strings.Contains(err.Error(), "429") {
+		t.Errorf("Expected a 429 error rather than a decode failure, got %v", err)
+	}
+}
+
+type stubGeocoder struct {
+	lat, lon float64
+	err      error
+	calls    int
+}
+
+func (s *stubGeocoder) Geocode(zip string) (float64, float64, error) {
+	s.calls++
+	return s.lat, s.lon, s.err
+}
+
+func TestChainGeocoderFallback(t *testing.T) {
+	first := &stubGeocoder{err: ErrZipNotFound}
+	second := &stubGeocoder{lat: 1, lon: 2}
+	third := &stubGeocoder{lat: 3, lon: 4}
+
+	lat, lon, err := ChainGeocoder{first, second, third}.Geocode("45140")
+	if err != nil {
+		t.Fatalf("Geocode failed: %v", err)
+	}
+	if lat != 1 || lon != 2 {
+		t.Errorf("Expected coordinates from second geocoder, got %f,%f", lat, lon)
+	}
+	if third.calls != 0 {
+		t.Errorf("Expected chain to stop at first hit, third geocoder called %d times", third.calls)
+	}
+}
+
+func TestChainGeocoderAllFail(t *testing.T) {
+	chain := ChainGeocoder{&stubGeocoder{err: ErrZipNotFound}, &stubGeocoder{err: errors.New("offline")}}
+
+	_, _, err := chain.Geocode("45140")
+	if !errors.Is(err, ErrZipNotFound) {
+		t.Errorf("Expected joined error to wrap ErrZipNotFound, got %v", err)
+	}
+}
+
+func TestNewGeocoder(t *testing.T) {
+	tests := []struct {
+		name     string
+		backends string
+		wantErr  bool
+		check    func(Geocoder) bool
+	}{
+		{
+			name:     "Default chain",
+			backends: "",
+			check:    func(g Geocoder) bool { c, ok := g.(ChainGeocoder); return ok && len(c) == 2 },
+		},
+		{
+			name:     "Offline only",
+			backends: "offline",
+			check:    func(g Geocoder) bool { _, ok := g.(*OfflineGeocoder); return ok },
+		},
+		{
+			name:     "Zippopotam only",
+			backends: " Zippopotam ",
+			check:    func(g Geocoder) bool { _, ok := g.(*ZippopotamGeocoder); return ok },
+		},
+		{
+			name:     "Unknown backend",
+			backends: "offline,google",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewGeocoder(tt.backends)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for backends %q", tt.backends)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewGeocoder(%q) failed: %v", tt.backends, err)
+			}
+			if !tt.check(g) {
+				t.Errorf("NewGeocoder(%q) returned unexpected geocoder %T", tt.backends, g)
+			}
+		})
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"net/url"
 	"fmt"
 	"io"
-	"strconv"
 	"strings"
 )
 
@@ -43,37 +42,9 @@ type OverpassResponse struct {
 	} `json:"elements"`
 }
 
-// zippopotamus api allows us to extract coordinate data from a zip code. connect to the api via net/http, parse lat/lgn data from the response, and return it
+// legacy helper that always goes to zippopotamus, prefer a Geocoder so the backend can be configured
 func GetCoordinatesFromZip(zip string) (float64, float64, error) {
-	zpURL := fmt.Sprintf("https://api.zippopotam.us/us/%s", zip)
-	resp, err := http.Get(zpURL)
-	if err != nil {
-		return 0, 0, fmt.Errorf("HTTP request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return 0, 0, fmt.Errorf("reading response failed: %w", err)
-	}
-
-	var data ZippoResponse
-	if err := json.Unmarshal(body, &data); err != nil {
-		return 0, 0, fmt.Errorf("JSON unmarshal failed: %w", err)
-	}
-
-	if len(data.Places) == 0 {
-		return 0, 0, fmt.Errorf("no places found for zip %s", zip)
-	}
-
-	place := data.Places[0]
-	lat, err1 := strconv.ParseFloat(place.Latitude, 64)
-	lon, err2 := strconv.ParseFloat(place.Longitude, 64)
-	if err1 != nil || err2 != nil {
-		return 0, 0, fmt.Errorf("invalid coordinates in API response")
-	}
-
-	return lat, lon, nil
+	return NewZippopotamGeocoder().Geocode(zip)
 }
 
 // overpass api to locate businesses around x radius of a lat/lgn point, send a query to the overpass api, parse the response, and return a list of businesses to geo-results.json
@@ -154,8 +125,9 @@ out;`,
 	return businesses, nil
 }
 
-func FindBusinessesByZip(zip string, radius int) ([]Business, error) {
-	lat, lon, err := GetCoordinatesFromZip(zip)
+// resolve the zip through the given geocoder, then look up businesses around it
+func FindBusinessesByZip(g Geocoder, zip string, radius int) ([]Business, error) {
+	lat, lon, err := g.Geocode(zip)
 	if err != nil {
 		return nil, err
 	}
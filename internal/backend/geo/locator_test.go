@@ -157,7 +157,7 @@ func TestFindBusinessesByZipIntegration(t *testing.T) {
 	zip := "45140" // Known zip code in Ohio, not too many businesses to scrape
 	radius := 2    // 2 mile radius (very small for testing)
 	
-	businesses, err := FindBusinessesByZip(zip, radius)
+	businesses, err := FindBusinessesByZip(DefaultGeocoder(), zip, radius)
 	if err != nil {
 		t.Fatalf("FindBusinessesByZip failed: %v", err)
 	}
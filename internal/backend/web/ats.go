@@ -0,0 +1,186 @@
+// recognizes applicant tracking systems (Greenhouse, Lever, Workday...) from links, iframes and script embeds, and pulls out the company's board identifier.
+package web
+
+import (
+	"net/url"
+	"strings"
+
+	"cliscraper/internal/backend/ats"
+)
+
+const (
+	ATSGreenhouse      = ats.Greenhouse
+	ATSLever           = ats.Lever
+	ATSWorkday         = "workday"
+	ATSBambooHR        = "bamboohr"
+	ATSICIMS           = "icims"
+	ATSJazzHR          = "jazzhr"
+	ATSPaylocity       = "paylocity"
+	ATSAshby           = ats.Ashby
+	ATSSmartRecruiters = "smartrecruiters"
+	ATSWorkable        = "workable"
+	ATSRecruitee       = "recruitee"
+	ATSBreezy          = "breezy"
+)
+
+// an ATS found on a page. Board identifies the company on the vendor side (greenhouse board token, lever site, workday tenant/site...)
+type ATSMatch struct {
+	Vendor string
+	Board  string
+	URL    string
+}
+
+// vendor display names for the tui
+var atsNames = map[string]string{
+	ATSGreenhouse:      "Greenhouse",
+	ATSLever:           "Lever",
+	ATSWorkday:         "Workday",
+	ATSBambooHR:        "BambooHR",
+	ATSICIMS:           "iCIMS",
+	ATSJazzHR:          "JazzHR",
+	ATSPaylocity:       "Paylocity",
+	ATSAshby:           "Ashby",
+	ATSSmartRecruiters: "SmartRecruiters",
+	ATSWorkable:        "Workable",
+	ATSRecruitee:       "Recruitee",
+	ATSBreezy:          "Breezy HR",
+}
+
+func ATSName(vendor string) string {
+	if name, ok := atsNames[vendor]; ok {
+		return name
+	}
+	return vendor
+}
+
+// matchers for hosts the vendor hands out per company, e.g. acme.bamboohr.com
+var atsSubdomains = []struct {
+	suffix string
+	vendor string
+}{
+	{".bamboohr.com", ATSBambooHR},
+	{".icims.com", ATSICIMS},
+	{".applytojob.com", ATSJazzHR},
+	{".recruitee.com", ATSRecruitee},
+	{".breezy.hr", ATSBreezy},
+}
+
+// subdomains that belong to the vendor itself, not to a customer
+var atsReservedSubdomains = map[string]bool{
+	"www": true, "api": true, "app": true, "static": true, "cdn": true, "assets": true,
+}
+
+// identify the ATS behind a url, ok is false for anything that is not a known ATS board
+func MatchATS(rawURL string) (ATSMatch, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return ATSMatch{}, false
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	segs := pathSegments(u.Path)
+	m := ATSMatch{URL: rawURL}
+
+	switch {
+	case host == "boards.greenhouse.io" || host == "job-boards.greenhouse.io" || host == "boards.eu.greenhouse.io":
+		m.Vendor = ATSGreenhouse
+		// embeds pass the board as ?for=, /embed/job_board/js?for=acme
+		if board := u.Query().Get("for"); board != "" {
+			m.Board = board
+		} else if len(segs) > 0 && segs[0] != "embed" {
+			m.Board = segs[0]
+		}
+	case host == "boards-api.greenhouse.io":
+		// /v1/boards/{board}/jobs
+		m.Vendor = ATSGreenhouse
+		if len(segs) >= 3 && segs[1] == "boards" {
+			m.Board = segs[2]
+		}
+	case host == "jobs.lever.co" || host == "jobs.eu.lever.co":
+		m.Vendor = ATSLever
+		if len(segs) > 0 {
+			m.Board = segs[0]
+		}
+	case host == "api.lever.co" || host == "api.eu.lever.co":
+		// /v0/postings/{site}
+		m.Vendor = ATSLever
+		if len(segs) >= 3 && segs[1] == "postings" {
+			m.Board = segs[2]
+		}
+	case host == "jobs.ashbyhq.com":
+		m.Vendor = ATSAshby
+		if len(segs) > 0 {
+			m.Board = segs[0]
+		}
+	case strings.HasSuffix(host, ".myworkdayjobs.com"):
+		// {tenant}.wd5.myworkdayjobs.com/{locale}/{site}, the board is tenant/site
+		m.Vendor = ATSWorkday
+		tenant := strings.SplitN(host, ".", 2)[0]
+		m.Board = tenant
+		for _, seg := range segs {
+			if isLocale(seg) {
+				continue
+			}
+			m.Board = tenant + "/" + seg
+			break
+		}
+	case host == "recruiting.paylocity.com" || host == "recruiting2.paylocity.com":
+		// /recruiting/jobs/All/{company guid}/...
+		m.Vendor = ATSPaylocity
+		for i, seg := range segs {
+			if strings.EqualFold(seg, "all") && i+1 < len(segs) {
+				m.Board = segs[i+1]
+				break
+			}
+		}
+	case host == "careers.smartrecruiters.com" || host == "jobs.smartrecruiters.com":
+		m.Vendor = ATSSmartRecruiters
+		if len(segs) > 0 {
+			m.Board = segs[0]
+		}
+	case host == "apply.workable.com":
+		m.Vendor = ATSWorkable
+		if len(segs) > 0 && segs[0] != "api" {
+			m.Board = segs[0]
+		}
+	default:
+		for _, sd := range atsSubdomains {
+			if !strings.HasSuffix(host, sd.suffix) {
+				continue
+			}
+			sub := strings.TrimSuffix(host, sd.suffix)
+			// careers-acme.icims.com and acme.icims.com both name the customer
+			sub = strings.TrimPrefix(sub, "careers-")
+			if sub == "" || strings.Contains(sub, ".") || atsReservedSubdomains[sub] {
+				return ATSMatch{}, false
+			}
+			m.Vendor = sd.vendor
+			m.Board = sub
+			break
+		}
+	}
+
+	if m.Vendor == "" {
+		return ATSMatch{}, false
+	}
+	return m, true
+}
+
+// every ATS referenced by the page through links, iframes, scripts or the BambooHR embed div, one per vendor/board
+func DetectATS(body, base string) []ATSMatch {
+	return ParseDocument(base, body).ATS()
+}
+
+func pathSegments(p string) []string {
+	var segs []string
+	for _, s := range strings.Split(p, "/") {
+		if s != "" {
+			segs = append(segs, s)
+		}
+	}
+	return segs
+}
+
+// en-US, fr-CA... as used in workday paths
+func isLocale(seg string) bool {
+	return len(seg) == 5 && seg[2] == '-'
+}
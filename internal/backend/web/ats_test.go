@@ -0,0 +1,133 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cliscraper/internal/backend/ats"
+)
+
+func TestMatchATS(t *testing.T) {
+	tests := []struct {
+		url    string
+		vendor string
+		board  string
+	}{
+		{url: "https://boards.greenhouse.io/acme", vendor: ATSGreenhouse, board: "acme"},
+		{url: "https://job-boards.greenhouse.io/acme/jobs/123", vendor: ATSGreenhouse, board: "acme"},
+		{url: "https://boards.greenhouse.io/embed/job_board/js?for=acme", vendor: ATSGreenhouse, board: "acme"},
+		{url: "https://boards-api.greenhouse.io/v1/boards/acme/jobs", vendor: ATSGreenhouse, board: "acme"},
+		{url: "https://jobs.lever.co/acme/3f2a", vendor: ATSLever, board: "acme"},
+		{url: "https://jobs.ashbyhq.com/acme", vendor: ATSAshby, board: "acme"},
+		{url: "https://acme.wd5.myworkdayjobs.com/en-US/External", vendor: ATSWorkday, board: "acme/External"},
+		{url: "https://acme.bamboohr.com/careers", vendor: ATSBambooHR, board: "acme"},
+		{url: "https://careers-acme.icims.com/jobs/search", vendor: ATSICIMS, board: "acme"},
+		{url: "https://acme.applytojob.com/apply", vendor: ATSJazzHR, board: "acme"},
+		{url: "https://recruiting.paylocity.com/recruiting/jobs/All/1a2b-3c4d/Acme-Inc", vendor: ATSPaylocity, board: "1a2b-3c4d"},
+		{url: "https://careers.smartrecruiters.com/Acme", vendor: ATSSmartRecruiters, board: "Acme"},
+		{url: "https://apply.workable.com/acme/", vendor: ATSWorkable, board: "acme"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			m, ok := MatchATS(tt.url)
+			if !ok {
+				t.Fatalf("Expected %s to be recognized", tt.url)
+			}
+			if m.Vendor != tt.vendor || m.Board != tt.board {
+				t.Errorf("Expected %s/%s, got %s/%s", tt.vendor, tt.board, m.Vendor, m.Board)
+			}
+		})
+	}
+
+	for _, u := range []string{"https://example.com/careers", "https://www.bamboohr.com/pricing", "not a url"} {
+		if m, ok := MatchATS(u); ok {
+			t.Errorf("Expected %s not to match, got %+v", u, m)
+		}
+	}
+}
+
+func TestDetectATS(t *testing.T) {
+	body := `<html><body>
+		<a href="https://jobs.lever.co/acme">Open roles</a>
+		<a href="https://jobs.lever.co/acme/123">Line cook</a>
+		<iframe src="https://boards.greenhouse.io/embed/job_board?for=acme"></iframe>
+		<script src="https://acme.bamboohr.com/js/embed.js"></script>
+		<div id="BambooHR" data-domain="acme.bamboohr.com"></div>
+		<a href="/about">About</a>
+	</body></html>`
+
+	found := DetectATS(body, "https://acme.com/")
+	var got []string
+	for _, m := range found {
+		got = append(got, m.Vendor+"/"+m.Board)
+	}
+	expected := "lever/acme greenhouse/acme bamboohr/acme"
+	if strings.Join(got, " ") != expected {
+		t.Errorf("Expected %q, got %q", expected, strings.Join(got, " "))
+	}
+}
+
+func TestScrapeRecordsATS(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `<html><body><a href="/work-with-us">Work with us</a></body></html>`)
+		case "/work-with-us":
+			fmt.Fprint(w, `<html><body><h1>Careers</h1><p>Apply for our cook position below</p>
+				<iframe src="https://boards.greenhouse.io/embed/job_board?for=acme"></iframe></body></html>`)
+		}
+	}))
+	defer srv.Close()
+
+	s := NewScraper("testbot")
+	s.Client = srv.Client()
+	s.Robots = nil
+	s.ATS = nil
+
+	found, err := s.Scrape(context.Background(), srv.URL+"/", []string{"cook"})
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if found.JobPage != srv.URL+"/work-with-us" {
+		t.Errorf("Expected careers page, got %q", found.JobPage)
+	}
+	if len(found.ATS) != 1 || found.ATS[0].Vendor != ATSGreenhouse || found.ATS[0].Board != "acme" {
+		t.Errorf("Expected greenhouse/acme, got %+v", found.ATS)
+	}
+}
+
+func TestScrapeReadsBoardPostings(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"jobs":[{"title":"Line Cook","absolute_url":"https://boards.greenhouse.io/acme/jobs/1"},
+			{"title":"Server","absolute_url":"https://boards.greenhouse.io/acme/jobs/2"}]}`)
+	}))
+	defer api.Close()
+
+	// the embed is rendered by javascript, so the page itself never mentions the role
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><body><div id="grnhse_app"></div>
+			<script src="https://boards.greenhouse.io/embed/job_board/js?for=acme"></script></body></html>`)
+	}))
+	defer site.Close()
+
+	s := NewScraper("testbot")
+	s.Client = site.Client()
+	s.Robots = nil
+	s.ATS = ats.NewAdapters(ats.Config{GreenhouseURL: api.URL, HTTPClient: api.Client()})
+
+	found, err := s.Scrape(context.Background(), site.URL+"/", []string{"cook"})
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if len(found.Jobs) != 1 || found.Jobs[0].Title != "Line Cook" {
+		t.Fatalf("Expected the cook posting, got %+v", found.Jobs)
+	}
+	if found.JobPage != "https://boards.greenhouse.io/acme" {
+		t.Errorf("Expected board page as job page, got %q", found.JobPage)
+	}
+}
@@ -0,0 +1,234 @@
+// on-disk http cache for fetched pages, one JSON file per url. a fresh copy is served without touching the network,
+// a stale one is revalidated with If-None-Match / If-Modified-Since. probe HEADs and sitemap GETs are kept for the TTL
+// as well, misses included, so rerunning a search with another title reads from disk.
+package web
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// how long a cached page is used as is when SCRAPER_CACHE_TTL is not set
+const DefaultCacheTTL = 12 * time.Hour
+
+type HTTPCache struct {
+	Dir string
+	// age past which an entry is revalidated before use
+	TTL time.Duration
+}
+
+// a cache in dir, or SCRAPER_CACHE_DIR when set. SCRAPER_CACHE_TTL (a duration, "0" turns the cache off) overrides the TTL
+func NewHTTPCacheFromEnv(dir string) (*HTTPCache, error) {
+	if env := os.Getenv("SCRAPER_CACHE_DIR"); env != "" {
+		dir = env
+	}
+	ttl := DefaultCacheTTL
+	if env := os.Getenv("SCRAPER_CACHE_TTL"); env != "" {
+		d, err := time.ParseDuration(env)
+		if err != nil && env != "0" {
+			return nil, fmt.Errorf("invalid SCRAPER_CACHE_TTL %q: %w", env, err)
+		}
+		ttl = d
+	}
+	if ttl <= 0 || dir == "" {
+		return nil, nil
+	}
+	return &HTTPCache{Dir: dir, TTL: ttl}, nil
+}
+
+// a stored page, Body is the raw html as served
+type cacheEntry struct {
+	URL          string    `json:"url"`
+	FinalURL     string    `json:"final_url"`
+	Redirects    []string  `json:"redirects,omitempty"`
+	ContentType  string    `json:"content_type,omitempty"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Body         []byte    `json:"body"`
+	StoredAt     time.Time `json:"stored_at"`
+	// status of a stored HEAD or sitemap result, 0 for pages (always 2xx) and unresolvable hosts
+	Status int `json:"status,omitempty"`
+}
+
+// the entry for pageURL, nil when there is none (or no cache)
+func (c *HTTPCache) get(pageURL string) *cacheEntry {
+	if c == nil {
+		return nil
+	}
+	data, err := os.ReadFile(c.path(pageURL))
+	if err != nil {
+		return nil
+	}
+	var e cacheEntry
+	if json.Unmarshal(data, &e) != nil || e.URL != pageURL {
+		return nil
+	}
+	return &e
+}
+
+func (c *HTTPCache) fresh(e *cacheEntry) bool {
+	return time.Since(e.StoredAt) < c.TTL
+}
+
+// store a 2xx page unless the server asked us not to
+func (c *HTTPCache) put(pageURL string, page fetchedPage, contentType string, header http.Header, raw []byte) {
+	if noStore(header) {
+		return
+	}
+	c.write(&cacheEntry{
+		URL:          pageURL,
+		FinalURL:     page.URL,
+		Redirects:    page.Redirects,
+		ContentType:  contentType,
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+		Body:         raw,
+		StoredAt:     time.Now(),
+	})
+}
+
+func noStore(header http.Header) bool {
+	return strings.Contains(strings.ToLower(header.Get("Cache-Control")), "no-store")
+}
+
+// keys for results other than a page GET, kept apart from the page entry of the same url
+func headKey(pageURL string) string       { return "HEAD " + pageURL }
+func sitemapKey(sitemapURL string) string { return "sitemap " + sitemapURL }
+
+// a fresh stored HEAD or sitemap result, nil without one. these are not revalidated, a stale one is asked for again
+func (c *HTTPCache) result(key string) *cacheEntry {
+	e := c.get(key)
+	if e == nil || !c.fresh(e) {
+		return nil
+	}
+	return e
+}
+
+// store a HEAD or sitemap result whatever its status, a rerun should not ask for a missing /careers again.
+// a rate limit or a server error may be gone next time and is not kept
+func (c *HTTPCache) putResult(key string, status int, finalURL string, header http.Header, body []byte) {
+	if c == nil || status == http.StatusTooManyRequests || status >= 500 || noStore(header) {
+		return
+	}
+	c.write(&cacheEntry{
+		URL:         key,
+		FinalURL:    finalURL,
+		Status:      status,
+		ContentType: header.Get("Content-Type"),
+		Body:        body,
+		StoredAt:    time.Now(),
+	})
+}
+
+// the server said 304, the entry is good for another TTL
+func (c *HTTPCache) revalidated(e *cacheEntry, resp *http.Response) {
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		e.ETag = etag
+	}
+	if modified := resp.Header.Get("Last-Modified"); modified != "" {
+		e.LastModified = modified
+	}
+	e.StoredAt = time.Now()
+	c.write(e)
+}
+
+// write through a temp file so readers never see half an entry. a failed write only loses the cached copy
+func (c *HTTPCache) write(e *cacheEntry) {
+	data, err := json.Marshal(e)
+	if err != nil || os.MkdirAll(c.Dir, os.ModePerm) != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(c.Dir, "entry-*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || os.Rename(tmp.Name(), c.path(e.URL)) != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// remove every entry, returns how many there were
+func (c *HTTPCache) Purge() (int, error) {
+	files, err := filepath.Glob(filepath.Join(c.Dir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for _, f := range files {
+		if err := os.Remove(f); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+func (c *HTTPCache) path(pageURL string) string {
+	sum := sha256.Sum256([]byte(pageURL))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// ask the server whether our copy is still current, nothing to ask without validators
+func (e *cacheEntry) conditional(req *http.Request) {
+	if e == nil {
+		return
+	}
+	if e.ETag != "" {
+		req.Header.Set("If-None-Match", e.ETag)
+	}
+	if e.LastModified != "" {
+		req.Header.Set("If-Modified-Since", e.LastModified)
+	}
+}
+
+func (e *cacheEntry) page() (fetchedPage, error) {
+	page := fetchedPage{URL: e.FinalURL, Redirects: e.Redirects}
+	body, err := decodeHTML(e.URL, e.ContentType, e.Body)
+	page.Body = body
+	return page, err
+}
+
+// cache use over one run, see WorkerPool.CacheStats
+type CacheStats struct {
+	// served from disk without a request
+	Hits atomic.Int64
+	// served from disk after a 304
+	Revalidated atomic.Int64
+	// downloaded and stored
+	Misses atomic.Int64
+}
+
+type cacheOutcome int
+
+const (
+	cacheHit cacheOutcome = iota
+	cacheRevalidated
+	cacheMiss
+)
+
+func (cs *CacheStats) count(o cacheOutcome) {
+	if cs == nil {
+		return
+	}
+	switch o {
+	case cacheHit:
+		cs.Hits.Add(1)
+	case cacheRevalidated:
+		cs.Revalidated.Add(1)
+	case cacheMiss:
+		cs.Misses.Add(1)
+	}
+}
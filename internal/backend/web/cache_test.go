@@ -0,0 +1,146 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchPageCache(t *testing.T) {
+	tests := []struct {
+		name        string
+		ttl         time.Duration
+		etag        string
+		requests    int
+		hits        int64
+		revalidated int64
+	}{
+		{name: "Fresh", ttl: time.Hour, requests: 1, hits: 1},
+		{name: "Stale with ETag", ttl: time.Nanosecond, etag: `"v1"`, requests: 2, revalidated: 1},
+		{name: "Stale without validators", ttl: time.Nanosecond, requests: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if tt.etag != "" {
+					if r.Header.Get("If-None-Match") == tt.etag {
+						w.WriteHeader(http.StatusNotModified)
+						return
+					}
+					w.Header().Set("ETag", tt.etag)
+				}
+				w.Header().Set("Content-Type", "text/html; charset=iso-8859-1")
+				w.Write([]byte("<p>Se\xf1or cocinero</p>"))
+			}))
+			defer srv.Close()
+
+			s := newTestScraper(srv)
+			s.Cache = &HTTPCache{Dir: t.TempDir(), TTL: tt.ttl}
+			s.cacheStats = &CacheStats{}
+			for i := 0; i < 2; i++ {
+				body, err := s.fetchBody(context.Background(), srv.URL+"/careers")
+				if err != nil {
+					t.Fatalf("fetchBody: %v", err)
+				}
+				if !strings.Contains(body, "Señor cocinero") {
+					t.Errorf("Fetch %d: expected the decoded page, got %q", i+1, body)
+				}
+			}
+			if requests != tt.requests {
+				t.Errorf("Expected %d requests, got %d", tt.requests, requests)
+			}
+			if hits, revalidated := s.cacheStats.Hits.Load(), s.cacheStats.Revalidated.Load(); hits != tt.hits || revalidated != tt.revalidated {
+				t.Errorf("Expected %d hits and %d revalidated, got %d and %d", tt.hits, tt.revalidated, hits, revalidated)
+			}
+			if misses := s.cacheStats.Misses.Load(); misses+s.cacheStats.Hits.Load()+s.cacheStats.Revalidated.Load() != 2 {
+				t.Errorf("Expected every fetch counted once, got %d misses", misses)
+			}
+		})
+	}
+}
+
+func TestScrapeRerunFromCache(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.URL.Path == "/" {
+			w.Write([]byte(`<html><body><p>Riverside Bistro, open daily</p></body></html>`))
+			return
+		}
+		// no sitemap and no careers paths, discovery comes up empty
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	s := newTestScraper(srv)
+	s.Cache = &HTTPCache{Dir: t.TempDir(), TTL: time.Hour}
+	for _, title := range []string{"cook", "server"} {
+		requests = nil
+		found, err := s.Scrape(context.Background(), srv.URL+"/", []string{title})
+		if err != nil || found.JobPage != "" {
+			t.Fatalf("Expected no job page for %s, got %q %v", title, found.JobPage, err)
+		}
+	}
+	// the homepage, the missing sitemap and every probe miss come from disk
+	if len(requests) != 0 {
+		t.Errorf("Expected a title-only rerun to send no requests, got %v", requests)
+	}
+}
+
+func TestHTTPCachePurge(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/private" {
+			w.Header().Set("Cache-Control", "no-store")
+		}
+		w.Write([]byte("<p>Careers</p>"))
+	}))
+	defer srv.Close()
+
+	s := newTestScraper(srv)
+	s.Cache = &HTTPCache{Dir: t.TempDir(), TTL: time.Hour}
+	for _, path := range []string{"/", "/careers", "/private"} {
+		if _, err := s.fetchBody(context.Background(), srv.URL+path); err != nil {
+			t.Fatalf("fetchBody: %v", err)
+		}
+	}
+	if n, err := s.Cache.Purge(); err != nil || n != 2 {
+		t.Errorf("Expected 2 purged entries, got %d %v", n, err)
+	}
+	if s.Cache.get(srv.URL+"/careers") != nil {
+		t.Error("Expected an empty cache after the purge")
+	}
+}
+
+func TestNewHTTPCacheFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		dir     string
+		ttl     string
+		enabled bool
+		err     bool
+	}{
+		{name: "Defaults", enabled: true},
+		{name: "Custom", dir: "/tmp/pages", ttl: "30m", enabled: true},
+		{name: "Off", ttl: "0"},
+		{name: "Invalid", ttl: "soon", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SCRAPER_CACHE_DIR", tt.dir)
+			t.Setenv("SCRAPER_CACHE_TTL", tt.ttl)
+			c, err := NewHTTPCacheFromEnv("output/cache")
+			if (err != nil) != tt.err || (c != nil) != tt.enabled {
+				t.Fatalf("Expected enabled %v and error %v, got %+v %v", tt.enabled, tt.err, c, err)
+			}
+			if tt.name == "Custom" && (c.Dir != "/tmp/pages" || c.TTL != 30*time.Minute) {
+				t.Errorf("Expected the env settings, got %+v", c)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+// scores how much a page looks like a real careers / openings page. each signal adds evidence, so a footer link saying "careers" stays well below a page with an openings list and an apply button.
+package web
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// signal names, stable so they can be counted and filtered on
+const (
+	SignalURLPath          = "url_path"
+	SignalHeading          = "heading"
+	SignalKeywordDensity   = "keyword_density"
+	SignalContextWords     = "context_words"
+	SignalTitleMatch       = "title_match"
+	SignalApplyButton      = "apply_button"
+	SignalApplicationForm  = "application_form"
+	SignalJobPostingMarkup = "job_posting_markup"
+	SignalATSBoard         = "ats_board"
+	SignalATSEmbed         = "ats_embed"
+	// set by the scraper when the board api returned matching openings
+	SignalBoardAPI = "board_api"
+	// set by the scraper on careers pages saying there are no openings
+	SignalNegativePhrase = "negative_phrase"
+)
+
+// how much each signal on its own convinces us, combined as independent evidence
+var signalWeights = map[string]float64{
+	SignalURLPath:          0.35,
+	SignalHeading:          0.3,
+	SignalKeywordDensity:   0.2,
+	SignalContextWords:     0.15,
+	SignalTitleMatch:       0.3,
+	SignalApplyButton:      0.2,
+	SignalApplicationForm:  0.25,
+	SignalJobPostingMarkup: 0.5,
+	SignalATSBoard:         0.6,
+	SignalATSEmbed:         0.45,
+}
+
+// phrases that make a <title> or heading read like a careers page
+var headingPhrases = []string{
+	"career", "jobs", "job openings", "open positions", "openings", "employment",
+	"join our team", "join the team", "join us", "work with us", "now hiring", "we're hiring", "we are hiring",
+}
+
+var applyPhrases = []string{"apply now", "apply today", "apply online", "apply here", "submit application", "start application", "apply"}
+
+// keyword mentions needed before density counts fully
+const fullDensityMentions = 5
+
+type Signal struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+	Detail string  `json:"detail,omitempty"`
+}
+
+type Classification struct {
+	// 0-1, 1 - product of (1 - weight) over the signals that fired
+	Score   float64
+	Signals []Signal
+}
+
+// "heading: Careers at Acme", strongest first
+func (c Classification) Reasons() []string {
+	reasons := make([]string, 0, len(c.Signals))
+	for _, s := range c.Signals {
+		r := s.Name
+		if s.Detail != "" {
+			r += ": " + s.Detail
+		}
+		reasons = append(reasons, r)
+	}
+	return reasons
+}
+
+func (c *Classification) add(name string, strength float64, detail string) {
+	if strength <= 0 {
+		return
+	}
+	if strength > 1 {
+		strength = 1
+	}
+	c.Signals = append(c.Signals, Signal{Name: name, Weight: signalWeights[name] * strength, Detail: detail})
+}
+
+// everything the classifier looks at, gathered by ParseDocument in its walk over the page
+type pageFeatures struct {
+	title      string
+	headings   []string
+	text       string
+	applyTexts []string
+	hasUpload  bool
+	formFields int
+}
+
+// score a page with the default profile, q is the search's title query (nil adds no title signal)
+func ClassifyPage(pageURL, body string, q *TitleQuery) Classification {
+	return DefaultProfile().Classify(pageURL, body, q)
+}
+
+func (p *Profile) Classify(pageURL, body string, q *TitleQuery) Classification {
+	return p.classify(ParseDocument(pageURL, body), q)
+}
+
+func (p *Profile) classify(doc *Document, q *TitleQuery) Classification {
+	var c Classification
+	f := doc.features
+	pageURL := doc.URL
+	lang := doc.Lang
+	kws := p.keywordsFor(lang)
+
+	if m, ok := MatchATS(pageURL); ok {
+		c.add(SignalATSBoard, 1, ATSName(m.Vendor))
+	} else if u, err := url.Parse(pageURL); err == nil {
+		path := foldAccents(strings.ToLower(u.Host + u.Path))
+		for _, kw := range kws {
+			if strings.Contains(path, kw) {
+				c.add(SignalURLPath, 1, kw)
+				break
+			}
+		}
+	}
+
+	for _, h := range append([]string{f.title}, f.headings...) {
+		h = foldAccents(strings.ToLower(h))
+		if containsAny(h, headingPhrases) || containsAny(h, kws) {
+			c.add(SignalHeading, 1, h)
+			break
+		}
+	}
+
+	text := doc.foldedText()
+	mentions := 0
+	for _, kw := range kws {
+		mentions += strings.Count(text, kw)
+	}
+	c.add(SignalKeywordDensity, float64(mentions)/fullDensityMentions, fmt.Sprintf("%d job keywords", mentions))
+
+	ctx := p.contextSet(lang)
+	distinct := map[string]bool{}
+	for _, w := range doc.Tokens() {
+		if ctx[w] {
+			distinct[w] = true
+		}
+	}
+	if len(distinct) >= 2 {
+		c.add(SignalContextWords, float64(len(distinct))/4, fmt.Sprintf("%d context words", len(distinct)))
+	}
+
+	if q != nil && p.matchesQuery(doc, q) {
+		c.add(SignalTitleMatch, 1, q.String())
+	}
+
+	for _, text := range f.applyTexts {
+		if containsAny(text, applyPhrases) {
+			c.add(SignalApplyButton, 1, text)
+			break
+		}
+	}
+
+	if f.hasUpload {
+		c.add(SignalApplicationForm, 1, "resume upload")
+	} else if f.formFields >= 4 && strings.Contains(f.text, "apply") {
+		c.add(SignalApplicationForm, 0.6, fmt.Sprintf("%d form fields", f.formFields))
+	}
+
+	if n := len(doc.JobPostings()); n > 0 {
+		c.add(SignalJobPostingMarkup, 1, fmt.Sprintf("%d postings", n))
+	}
+
+	if embeds := doc.ATS(); len(embeds) > 0 {
+		c.add(SignalATSEmbed, 1, ATSName(embeds[0].Vendor))
+	}
+
+	sort.SliceStable(c.Signals, func(i, j int) bool { return c.Signals[i].Weight > c.Signals[j].Weight })
+	missing := 1.0
+	for _, s := range c.Signals {
+		missing *= 1 - s.Weight
+	}
+	c.Score = math.Round((1-missing)*100) / 100
+	return c
+}
+
+func containsAny(s string, phrases []string) bool {
+	for _, p := range phrases {
+		if strings.Contains(s, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package web
+
+import (
+	"context"
+	"testing"
+)
+
+const footerOnlyPage = `<html><head><title>Joe's Diner</title></head><body>
+<h1>Welcome to Joe's Diner</h1><p>Best breakfast in town, our cook makes everything fresh.</p>
+<footer><a href="/careers">Careers</a></footer></body></html>`
+
+const openingsPage = `<html><head><title>Careers at Joe's Diner</title></head><body>
+<h1>Join our team</h1>
+<p>We are hiring! Current openings:</p>
+<ul><li>Line cook - full-time, competitive pay and benefits</li><li>Server - part-time</li></ul>
+<a href="/apply">Apply now</a>
+<form action="/apply"><input name="name"><input name="email"><input type="file" name="resume"><input type="submit" value="Submit application"></form>
+</body></html>`
+
+func hasSignal(c Classification, name string) bool {
+	for _, s := range c.Signals {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestClassifyPage(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		body     string
+		minScore float64
+		maxScore float64
+		signals  []string
+	}{
+		{
+			name:     "Footer link only",
+			url:      "https://joesdiner.com/",
+			body:     footerOnlyPage,
+			maxScore: 0.4,
+		},
+		{
+			name:     "Openings page",
+			url:      "https://joesdiner.com/careers",
+			body:     openingsPage,
+			minScore: 0.8,
+			signals:  []string{SignalURLPath, SignalHeading, SignalTitleMatch, SignalApplyButton, SignalApplicationForm},
+		},
+		{
+			name:     "ATS board",
+			url:      "https://boards.greenhouse.io/acme",
+			body:     `<html><body><p>Line cook</p></body></html>`,
+			minScore: 0.6,
+			signals:  []string{SignalATSBoard},
+		},
+		{
+			name:     "Embedded board",
+			url:      "https://acme.com/team",
+			body:     `<html><body><iframe src="https://jobs.lever.co/acme"></iframe></body></html>`,
+			minScore: 0.45,
+			signals:  []string{SignalATSEmbed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := ClassifyPage(tt.url, tt.body, TitlesQuery([]string{"cook"}))
+			if c.Score < tt.minScore || (tt.maxScore > 0 && c.Score > tt.maxScore) {
+				t.Errorf("Score %v outside [%v, %v], signals %v", c.Score, tt.minScore, tt.maxScore, c.Reasons())
+			}
+			if c.Score < 0 || c.Score > 1 {
+				t.Errorf("Expected score within 0-1, got %v", c.Score)
+			}
+			for _, name := range tt.signals {
+				if !hasSignal(c, name) {
+					t.Errorf("Expected signal %s, got %v", name, c.Reasons())
+				}
+			}
+		})
+	}
+}
+
+func TestClassifyPageReasonsStrongestFirst(t *testing.T) {
+	c := ClassifyPage("https://joesdiner.com/careers", openingsPage, TitlesQuery([]string{"cook"}))
+	for i := 1; i < len(c.Signals); i++ {
+		if c.Signals[i].Weight > c.Signals[i-1].Weight {
+			t.Fatalf("Expected signals sorted by weight, got %v", c.Signals)
+		}
+	}
+	if reasons := c.Reasons(); len(reasons) != len(c.Signals) || reasons[0] == "" {
+		t.Errorf("Expected one reason per signal, got %v", reasons)
+	}
+}
+
+func TestScrapeMinScore(t *testing.T) {
+	_, srv := newTestSite(t, map[string]string{
+		"/":        `<html><body><a href="/careers">Careers</a></body></html>`,
+		"/careers": cookOpening,
+	})
+
+	s := newTestScraper(srv)
+	found, err := s.ScrapeQuery(context.Background(), srv.URL+"/", TitlesQuery([]string{"cook"}))
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if found.JobPage != srv.URL+"/careers" || found.Score == 0 || len(found.Reasons) == 0 {
+		t.Fatalf("Expected a scored careers page, got %+v", found)
+	}
+
+	s.MinScore = found.Score + 0.01
+	found, err = s.ScrapeQuery(context.Background(), srv.URL+"/", TitlesQuery([]string{"cook"}))
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if found.JobPage != "" {
+		t.Errorf("Expected page below min score to be rejected, got %q", found.JobPage)
+	}
+}
@@ -0,0 +1,279 @@
+// bounded breadth-first crawl of one site looking for its careers page. links are scored by url and anchor text so the budget goes to the likely ones first.
+package web
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultMaxDepth = 3
+	defaultMaxPages = 15
+)
+
+// link scores, anything at 0 is not followed
+const (
+	scoreHub     = 1  // about/company pages that often hold the careers link
+	scoreKeyword = 5  // careers wording in the url or the anchor text
+	scoreATS     = 20 // straight to the job board
+)
+
+// anchor text that points at a careers page even when the url gives nothing away ("/page?id=12")
+var careersAnchorPhrases = []string{
+	"career", "jobs", "job openings", "open positions", "openings", "employment",
+	"join our team", "join the team", "join us", "work here", "work with us", "work for us",
+	"we're hiring", "we are hiring", "now hiring", "hiring", "opportunities", "apply now",
+}
+
+// sections that commonly link on to the careers page, Home → About → Careers
+var hubWords = []string{"about", "company", "team", "who-we-are", "who we are", "our story", "people", "culture", "nosotros", "quienes somos", "empresa"}
+
+// pdf names and link text of printable applications and job descriptions
+var pdfPhrases = []string{"application", "apply", "job description", "job-description", "position"}
+
+// links to files or places we never want to fetch
+var skipExtensions = map[string]bool{
+	".pdf": true, ".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".svg": true, ".webp": true,
+	".zip": true, ".doc": true, ".docx": true, ".xls": true, ".xlsx": true, ".mp4": true, ".mp3": true,
+	".css": true, ".js": true, ".ico": true, ".xml": true,
+}
+
+type anchor struct {
+	URL  string
+	Text string
+}
+
+type crawlItem struct {
+	url   string
+	depth int
+	score int
+}
+
+// how promising a link is as a way to the careers page
+func (p *Profile) scoreLink(a anchor) int {
+	if _, ok := MatchATS(a.URL); ok {
+		return scoreATS
+	}
+
+	u, err := url.Parse(a.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return 0
+	}
+	if skipExtensions[strings.ToLower(path.Ext(u.Path))] || p.SkipURL(a.URL) {
+		return 0
+	}
+
+	// the linked page's language is unknown until fetched, so every language's wording counts
+	score := 0
+	urlLower := foldAccents(strings.ToLower(u.Host + u.Path))
+	for _, kw := range p.allKeywords() {
+		if strings.Contains(urlLower, kw) {
+			score += scoreKeyword
+			break
+		}
+	}
+	text := foldAccents(strings.ToLower(a.Text))
+	if containsAny(text, careersAnchorPhrases) || containsAny(text, p.allKeywords()) {
+		score += scoreKeyword
+	}
+	if score > 0 {
+		return score
+	}
+
+	for _, w := range hubWords {
+		if strings.Contains(text, w) || strings.Contains(urlLower, w) {
+			return scoreHub
+		}
+	}
+	return 0
+}
+
+// same site means the root's host, with or without www., or one of its subdomains such as careers.example.com
+func sameSite(rootHost, link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	return host == rootHost || strings.HasSuffix(host, "."+rootHost)
+}
+
+// key for the visited set, fragments and trailing slashes do not make a new page
+func crawlKey(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	u.Fragment = ""
+	u.Host = strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	return u.String()
+}
+
+// walk the site level by level, best scored links first within a level, until a page matches or the budget runs out.
+// a root that only mentions jobs is kept as the fallback, a dedicated careers page found later wins over it.
+// a careers page saying there are no openings only comes back when nothing else matched, the crawl keeps looking past it.
+// a root without careers links adds its sitemap's best urls to the first level (see sitemap.go).
+// when no careers page turned up, the profile's probe paths are tried before settling for either (see probe.go)
+func (s *Scraper) crawl(ctx context.Context, rootDoc *Document, q *TitleQuery) (Finding, error) {
+	rootURL := rootDoc.URL
+	root, err := url.Parse(rootURL)
+	if err != nil {
+		return Finding{}, err
+	}
+	rootHost := strings.TrimPrefix(strings.ToLower(root.Host), "www.")
+
+	maxDepth, maxPages := s.MaxDepth, s.MaxPages
+	if maxDepth <= 0 {
+		maxDepth = defaultMaxDepth
+	}
+	if maxPages <= 0 {
+		maxPages = defaultMaxPages
+	}
+
+	var (
+		fallback *Finding
+		closed   *Finding // first careers page with no openings
+		blocked  error
+		fetched  = 1 // the root
+		visited  = map[string]bool{crawlKey(rootURL): true}
+		docs     []string
+		probes   int
+	)
+	done := func(f Finding, err error) (Finding, error) {
+		f.Documents = docs
+		f.Probes = probes
+		return f, err
+	}
+	docs = s.notePDFs(docs, rootDoc, rootHost, visited)
+
+	if page, ok := s.matchPage(rootDoc, q); ok && page.NotHiring != "" {
+		closed = &page
+	} else if ok {
+		// the root itself is the careers page (its url says so, or it is an ATS board)
+		if s.profile().scoreLink(anchor{URL: rootURL}) >= scoreKeyword {
+			return done(page, nil)
+		}
+		fallback = &page
+	}
+
+	frontier := s.nextLevel(nil, rootDoc, 1, rootHost, visited)
+	// no careers wording among the root's links, the menu may be javascript: ask the sitemaps
+	if len(frontier) == 0 || frontier[0].score < scoreKeyword {
+		frontier = append(s.sitemapCandidates(ctx, root, rootHost, visited), frontier...)
+	}
+	for depth := 1; depth <= maxDepth && len(frontier) > 0; depth++ {
+		var next []crawlItem
+		for _, item := range frontier {
+			if fetched >= maxPages {
+				break
+			}
+			// give up on the remaining links once the job is cancelled or out of time
+			if err := ctx.Err(); err != nil {
+				return Finding{}, err
+			}
+
+			body, err := s.fetchBody(ctx, item.url)
+			fetched++
+			if err != nil {
+				var cte *ContentTypeError
+				switch {
+				case errors.Is(err, ErrRobotsDisallowed) && blocked == nil:
+					blocked = err
+				case errors.As(err, &cte) && cte.PDF():
+					// a careers link that turned out to be a pdf
+					docs = append(docs, item.url)
+				}
+				continue
+			}
+			// debug print
+			//fmt.Printf("Checking candidate link: %s (depth %d, score %d)\n", item.url, item.depth, item.score)
+
+			doc := ParseDocument(item.url, body)
+			docs = s.notePDFs(docs, doc, rootHost, visited)
+			if page, ok := s.matchPage(doc, q); ok {
+				if page.NotHiring == "" {
+					return done(page, nil)
+				}
+				if closed == nil {
+					closed = &page
+				}
+			}
+			// job boards are leaves, their links go to postings and other companies
+			if _, isATS := MatchATS(item.url); !isATS && depth < maxDepth {
+				next = s.nextLevel(next, doc, depth+1, rootHost, visited)
+			}
+		}
+		frontier = next
+	}
+
+	if err := ctx.Err(); err != nil {
+		return Finding{}, err
+	}
+	page, ok, probes := s.probe(ctx, root, rootHost, visited, q)
+	if err := ctx.Err(); err != nil {
+		return Finding{}, err
+	}
+	if ok {
+		page.ProbeHit = true
+		if page.NotHiring == "" {
+			return done(page, nil)
+		}
+		if closed == nil {
+			closed = &page
+		}
+	}
+	if fallback != nil {
+		return done(*fallback, nil)
+	}
+	if closed != nil {
+		return done(*closed, nil)
+	}
+	return done(Finding{}, blocked)
+}
+
+// same-site pdf links worded like a job description or an application, noted instead of fetched
+func (s *Scraper) notePDFs(docs []string, doc *Document, rootHost string, visited map[string]bool) []string {
+	p := s.profile()
+	for _, a := range doc.anchors {
+		u, err := url.Parse(a.URL)
+		if err != nil || strings.ToLower(path.Ext(u.Path)) != ".pdf" || !sameSite(rootHost, a.URL) {
+			continue
+		}
+		text := foldAccents(strings.ToLower(u.Path + " " + a.Text))
+		if !containsAny(text, p.allKeywords()) && !containsAny(text, careersAnchorPhrases) && !containsAny(text, pdfPhrases) {
+			continue
+		}
+		if key := crawlKey(a.URL); !visited[key] {
+			visited[key] = true
+			docs = append(docs, a.URL)
+		}
+	}
+	return docs
+}
+
+// add the page's scored, unvisited links to the next level, keeping the level sorted best first
+func (s *Scraper) nextLevel(level []crawlItem, doc *Document, depth int, rootHost string, visited map[string]bool) []crawlItem {
+	for _, a := range doc.anchors {
+		score := s.profile().scoreLink(a)
+		if score == 0 {
+			continue
+		}
+		// off-site links only count when they are the company's job board
+		if score < scoreATS && !sameSite(rootHost, a.URL) {
+			continue
+		}
+		key := crawlKey(a.URL)
+		if visited[key] {
+			continue
+		}
+		visited[key] = true
+		level = append(level, crawlItem{url: a.URL, depth: depth, score: score})
+	}
+	sort.SliceStable(level, func(i, j int) bool { return level[i].score > level[j].score })
+	return level
+}
@@ -0,0 +1,163 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// site served from a map of path -> html, recording every path fetched
+type testSite struct {
+	mu      sync.Mutex
+	pages   map[string]string
+	fetched []string
+}
+
+func newTestSite(t *testing.T, pages map[string]string) (*testSite, *httptest.Server) {
+	site := &testSite{pages: pages}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		site.mu.Lock()
+		site.fetched = append(site.fetched, r.URL.RequestURI())
+		site.mu.Unlock()
+		body, ok := pages[r.URL.RequestURI()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return site, srv
+}
+
+func newTestScraper(srv *httptest.Server) *Scraper {
+	s := NewScraper("testbot")
+	s.Client = srv.Client()
+	s.Robots = nil
+	s.ATS = nil
+	return s
+}
+
+// scraper that only crawls, without the careers-path probes
+func newCrawlOnlyScraper(t *testing.T, srv *httptest.Server) *Scraper {
+	s := newTestScraper(srv)
+	p, err := LoadProfile(strings.NewReader(`{"name": "crawl-only", "probe_paths": []}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Profile = p
+	return s
+}
+
+const cookOpening = `<html><body><h1>Open Positions</h1><p>We are hiring a line cook, apply today</p></body></html>`
+
+func TestCrawlFollowsAnchorTextThroughHubPages(t *testing.T) {
+	_, srv := newTestSite(t, map[string]string{
+		"/":          `<html><body><a href="/page?id=2">About Us</a><a href="/menu">Menu</a></body></html>`,
+		"/page?id=2": `<html><body><a href="/page?id=3">Work Here</a></body></html>`,
+		"/page?id=3": cookOpening,
+	})
+
+	found, err := newTestScraper(srv).Scrape(context.Background(), srv.URL+"/", []string{"cook"})
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if found.JobPage != srv.URL+"/page?id=3" {
+		t.Errorf("Expected the page behind Work Here, got %q", found.JobPage)
+	}
+}
+
+func TestCrawlRespectsDepth(t *testing.T) {
+	site, srv := newTestSite(t, map[string]string{
+		"/":        `<html><body><a href="/about">About</a></body></html>`,
+		"/about":   `<html><body><a href="/team">Our team</a></body></html>`,
+		"/team":    `<html><body><a href="/careers">Careers</a></body></html>`,
+		"/careers": cookOpening,
+	})
+
+	s := newCrawlOnlyScraper(t, srv)
+	s.MaxDepth = 2
+	found, err := s.Scrape(context.Background(), srv.URL+"/", []string{"cook"})
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if found.JobPage != "" {
+		t.Errorf("Expected careers page at depth 3 to be out of reach, got %q", found.JobPage)
+	}
+	for _, p := range site.fetched {
+		if p == "/careers" {
+			t.Error("Fetched a page beyond the max depth")
+		}
+	}
+}
+
+func TestCrawlRespectsPageBudgetAndSite(t *testing.T) {
+	external := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Fetched a careers link on another site")
+	}))
+	defer external.Close()
+
+	site, srv := newTestSite(t, map[string]string{
+		"/": `<html><body>
+			<a href="` + external.URL + `/careers">Partner careers</a>
+			<a href="/jobs/1">Jobs 1</a><a href="/jobs/2">Jobs 2</a><a href="/jobs/3">Jobs 3</a>
+			<a href="/jobs/4">Jobs 4</a><a href="/jobs/5">Jobs 5</a></body></html>`,
+	})
+
+	s := newCrawlOnlyScraper(t, srv)
+	s.MaxPages = 3
+	if _, err := s.Scrape(context.Background(), srv.URL+"/", []string{"cook"}); err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if len(site.fetched) != 3 {
+		t.Errorf("Expected 3 fetched pages, got %d: %v", len(site.fetched), site.fetched)
+	}
+}
+
+func TestCrawlPrefersCareersPageOverRoot(t *testing.T) {
+	root := `<html><body><p>Family diner. Cook position open, apply inside or visit our careers page.</p>
+		<a href="/careers">Careers</a></body></html>`
+
+	_, srv := newTestSite(t, map[string]string{"/": root, "/careers": cookOpening})
+	found, err := newTestScraper(srv).Scrape(context.Background(), srv.URL+"/", []string{"cook"})
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if found.JobPage != srv.URL+"/careers" {
+		t.Errorf("Expected the dedicated careers page, got %q", found.JobPage)
+	}
+
+	// without a careers page that matches, the root is the answer
+	_, srv = newTestSite(t, map[string]string{"/": root})
+	found, err = newTestScraper(srv).Scrape(context.Background(), srv.URL+"/", []string{"cook"})
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if found.JobPage != srv.URL+"/" {
+		t.Errorf("Expected fallback to root, got %q", found.JobPage)
+	}
+}
+
+func TestScoreLink(t *testing.T) {
+	tests := []struct {
+		anchor   anchor
+		expected int
+	}{
+		{anchor: anchor{URL: "https://acme.com/careers", Text: "Careers"}, expected: 2 * scoreKeyword},
+		{anchor: anchor{URL: "https://acme.com/p/12", Text: "Join our team"}, expected: scoreKeyword},
+		{anchor: anchor{URL: "https://acme.com/about-us", Text: "Our story"}, expected: scoreHub},
+		{anchor: anchor{URL: "https://jobs.lever.co/acme", Text: "See openings"}, expected: scoreATS},
+		{anchor: anchor{URL: "https://acme.com/careers/handbook.pdf", Text: "Careers handbook"}, expected: 0},
+		{anchor: anchor{URL: "mailto:jobs@acme.com", Text: "Email jobs"}, expected: 0},
+		{anchor: anchor{URL: "https://acme.com/menu", Text: "Menu"}, expected: 0},
+	}
+	for _, tt := range tests {
+		if got := DefaultProfile().scoreLink(tt.anchor); got != tt.expected {
+			t.Errorf("scoreLink(%+v) = %d, expected %d", tt.anchor, got, tt.expected)
+		}
+	}
+}
@@ -3,8 +3,6 @@ package web
 
 import (
 	"strings"
-
-	"golang.org/x/net/html"
 )
 
 var JobPageKeywords = []string{
@@ -12,17 +10,28 @@ var JobPageKeywords = []string{
 	"opportunities", "work-with-us", "hiring",
 }
 
+// IsJobPage with the default profile
 func IsJobPage(url, body string) bool {
-	urlLower := strings.ToLower(url)
-	for _, kw := range JobPageKeywords {
+	return DefaultProfile().IsJobPage(url, body)
+}
+
+// keywords of the page's language are checked on top of the English ones
+func (p *Profile) IsJobPage(url, body string) bool {
+	return p.isJobPage(ParseDocument(url, body))
+}
+
+func (p *Profile) isJobPage(doc *Document) bool {
+	kws := p.keywordsFor(doc.Lang)
+	urlLower := foldAccents(strings.ToLower(doc.URL))
+	for _, kw := range kws {
 		if strings.Contains(urlLower, kw) {
 			return true
 		}
 	}
 
 	// filter out scripts/styles, then scan for keywords
-	text := extractVisibleText(body)
-	for _, kw := range JobPageKeywords {
+	text := doc.foldedText()
+	for _, kw := range kws {
 		if strings.Contains(text, kw) {
 			return true
 		}
@@ -30,32 +39,13 @@ func IsJobPage(url, body string) bool {
 	return false
 }
 
+// whether the page mentions one of the titles, as a phrase or through a synonym, close to hiring words
 func MatchesJobTitle(body string, titles []string) bool {
 	// if no title is provided, assume true and search for any job page 
-	if len(titles) == 0 {
-		return true
-	}
-	text := extractVisibleText(body)
-
-	// build quick word index
-	words := strings.FieldsFunc(text, func(r rune) bool {
-		return r < 'a' || r > 'z' // split on anything non a–z
-	})
-	wordSet := make(map[string]struct{}, len(words))
-	for _, w := range words {
-		wordSet[w] = struct{}{}
-	}
-
-	for _, t := range titles {
-		tLower := strings.ToLower(t)
-		if _, ok := wordSet[tLower]; ok && hasNearbyContext(words, tLower) {
-			return true
-		}
-	}
-	return false
+	return TitlesQuery(titles).MatchesPage(body)
 }
 
-// context words to avoid false hits
+// context words to avoid false hits, English. other languages have theirs in Languages
 var contextWords = []string{
 	"apply", "opening", "position", "role", "responsibilities",
 	"full-time", "part-time", "hiring", "join", "career", "benefits",
@@ -63,48 +53,35 @@ var contextWords = []string{
 
 // DOM text extractor
 func extractVisibleText(htmlBody string) string {
-	doc, err := html.Parse(strings.NewReader(htmlBody))
-	if err != nil {
-		return strings.ToLower(htmlBody) // fallback
-	}
+	return ParseDocument("", htmlBody).Text
+}
 
-	var sb strings.Builder
-	var walk func(*html.Node)
-	walk = func(n *html.Node) {
-		if n.Type == html.TextNode {
-			txt := strings.TrimSpace(n.Data)
-			if txt != "" {
-				sb.WriteString(txt)
-				sb.WriteByte(' ')
-			}
-		}
-		if n.Type == html.ElementNode {
-			switch strings.ToLower(n.Data) {
-			case "script", "style", "noscript":
-				return
-			}
-		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			walk(c)
+// words are plain lowercase words, lang picks the default profile's context words on top of the English ones
+func hasNearbyContext(words []string, token, lang string) bool {
+	stemmed := make([]string, len(words))
+	for i, w := range words {
+		stemmed[i] = stem(foldAccents(w))
+	}
+	p := DefaultProfile()
+	ctx := p.contextSet(lang)
+	for i, w := range words {
+		if w == token && hasContextAround(stemmed, i, 1, ctx, p.window()) {
+			return true
 		}
 	}
-	walk(doc)
-
-	return strings.ToLower(sb.String())
+	return false
 }
 
-func hasNearbyContext(words []string, token string) bool {
-	for i, w := range words {
-		if w == token {
-			start := max(0, i-8)
-			end := min(len(words), i+8)
-			for _, ctx := range contextWords {
-				for j := start; j < end; j++ {
-					if words[j] == ctx {
-						return true
-					}
-				}
-			}
+// one of the context words within window words either side of the n-word phrase at i, words as from titleTokens
+func hasContextAround(words []string, i, n int, ctx map[string]bool, window int) bool {
+	start := max(0, i-window)
+	end := min(len(words), i+n+window)
+	for j := start; j < end; j++ {
+		if j >= i && j < i+n {
+			continue
+		}
+		if ctx[words[j]] {
+			return true
 		}
 	}
 	return false
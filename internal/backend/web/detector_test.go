@@ -216,7 +216,7 @@ func TestHasNearbyContext(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := hasNearbyContext(tt.words, tt.token)
+			result := hasNearbyContext(tt.words, tt.token, DefaultLanguage)
 			if result != tt.expected {
 				t.Errorf("hasNearbyContext(%v, %q) = %v, want %v", tt.words, tt.token, result, tt.expected)
 			}
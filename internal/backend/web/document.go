@@ -0,0 +1,214 @@
+// one analysis pass per fetched page. the body is parsed once and a single walk collects everything the detectors
+// look at: visible text, links with their anchor text, title and headings, form controls, ATS references and
+// JobPosting markup. the detectors, the classifier and the crawler all read from the same Document.
+package web
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// the lazily built parts are cached without locking, a Document belongs to the worker that fetched the page
+type Document struct {
+	URL  string
+	Body string
+	// lowercase visible text, script, style and noscript left out
+	Text string
+	// language code, declared by <html lang> or guessed from the text
+	Lang string
+	// <title> and <meta name="description">
+	Title       string
+	Description string
+
+	features pageFeatures
+	anchors  []anchor
+	// href/src values that may point at an ATS, resolved against URL
+	atsRefs []string
+	// raw JobPosting objects from JSON-LD and microdata
+	postingItems []map[string]interface{}
+
+	// built on first use
+	tokens   []string
+	index    map[string][]int
+	folded   string
+	postings []JobPosting
+	ats      []ATSMatch
+	parsed   struct{ postings, ats bool }
+}
+
+// parse body once and collect everything the detectors need
+func ParseDocument(pageURL, body string) *Document {
+	d := &Document{URL: pageURL, Body: body}
+	root, err := html.Parse(strings.NewReader(body))
+	if err != nil {
+		d.Text = strings.ToLower(body) // fallback
+		d.features.text = d.Text
+		d.Lang = guessLanguage(d.Text)
+		return d
+	}
+
+	var sb strings.Builder
+	var declared string
+	var walk func(n *html.Node, inPosting bool)
+	walk = func(n *html.Node, inPosting bool) {
+		switch n.Type {
+		case html.TextNode:
+			if txt := strings.TrimSpace(n.Data); txt != "" {
+				sb.WriteString(txt)
+				sb.WriteByte(' ')
+			}
+		case html.ElementNode:
+			d.collectRefs(n)
+			switch n.Data {
+			case "html":
+				// lang only counts on the root element
+				if lang, ok := attr(n, "lang"); ok {
+					declared = primarySubtag(lang)
+				}
+			case "script":
+				if strings.Contains(strings.ToLower(attrValue(n, "type")), "ld+json") {
+					d.postingItems = append(d.postingItems, jsonLDPostings(nodeText(n))...)
+				}
+				return
+			case "style", "noscript":
+				return
+			case "title":
+				d.Title = strings.TrimSpace(nodeText(n))
+				d.features.title = strings.ToLower(d.Title)
+			case "meta":
+				if strings.EqualFold(attrValue(n, "name"), "description") {
+					d.Description = strings.TrimSpace(attrValue(n, "content"))
+				}
+			case "h1", "h2":
+				if h := strings.Join(strings.Fields(nodeText(n)), " "); h != "" {
+					d.features.headings = append(d.features.headings, h)
+				}
+			case "a":
+				d.addAnchor(n)
+				d.features.applyTexts = append(d.features.applyTexts, strings.ToLower(strings.Join(strings.Fields(nodeText(n)), " ")))
+			case "button":
+				d.features.applyTexts = append(d.features.applyTexts, strings.ToLower(strings.Join(strings.Fields(nodeText(n)), " ")))
+			case "input":
+				switch strings.ToLower(attrValue(n, "type")) {
+				case "file":
+					d.features.hasUpload = true
+				case "submit":
+					d.features.applyTexts = append(d.features.applyTexts, strings.ToLower(attrValue(n, "value")))
+				}
+				d.features.formFields++
+			case "textarea", "select":
+				d.features.formFields++
+			}
+			// nested postings inside a posting are not a thing, only the outer one is read
+			if _, ok := attr(n, "itemscope"); ok && !inPosting && isJobPostingType(attrValue(n, "itemtype")) {
+				d.postingItems = append(d.postingItems, microdataItem(n))
+				inPosting = true
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, inPosting)
+		}
+	}
+	walk(root, false)
+
+	d.Text = strings.ToLower(sb.String())
+	d.features.text = d.Text
+	d.Lang = declared
+	if d.Lang == "" {
+		d.Lang = guessLanguage(d.Text)
+	} else if _, ok := Languages[d.Lang]; !ok {
+		d.Lang = DefaultLanguage
+	}
+	return d
+}
+
+// <a href> with its visible text, icon links fall back to their aria-label or title
+func (d *Document) addAnchor(n *html.Node) {
+	href := resolveURL(d.URL, attrValue(n, "href"))
+	if href == "" {
+		return
+	}
+	text := strings.Join(strings.Fields(nodeText(n)), " ")
+	if text == "" {
+		text = attrValue(n, "aria-label") + " " + attrValue(n, "title")
+	}
+	d.anchors = append(d.anchors, anchor{URL: href, Text: strings.TrimSpace(text)})
+}
+
+// links, iframes, scripts and forms that may lead to an ATS, plus the BambooHR embed div
+func (d *Document) collectRefs(n *html.Node) {
+	for _, a := range n.Attr {
+		switch {
+		case a.Key == "href" && (n.Data == "a" || n.Data == "link"),
+			a.Key == "src" && (n.Data == "iframe" || n.Data == "script"),
+			a.Key == "data-src" && n.Data == "iframe",
+			a.Key == "action" && n.Data == "form":
+			d.atsRefs = append(d.atsRefs, a.Val)
+		case a.Key == "data-domain":
+			// <div id="BambooHR" data-domain="acme.bamboohr.com">
+			d.atsRefs = append(d.atsRefs, "https://"+strings.TrimPrefix(strings.TrimPrefix(a.Val, "https://"), "http://"))
+		}
+	}
+}
+
+// visible text as titleTokens, the form title queries are matched against
+func (d *Document) Tokens() []string {
+	if d.tokens == nil {
+		d.tokens = titleTokens(d.Text)
+	}
+	return d.tokens
+}
+
+// positions of every token, so a phrase lookup starts from its first word instead of scanning the page
+func (d *Document) tokenIndex() map[string][]int {
+	if d.index == nil {
+		tokens := d.Tokens()
+		d.index = make(map[string][]int, len(tokens))
+		for i, tok := range tokens {
+			d.index[tok] = append(d.index[tok], i)
+		}
+	}
+	return d.index
+}
+
+// accent folded visible text
+func (d *Document) foldedText() string {
+	if d.folded == "" && d.Text != "" {
+		d.folded = foldAccents(d.Text)
+	}
+	return d.folded
+}
+
+// JobPosting entries from the page's JSON-LD blocks and microdata, postings without a url point at the page
+func (d *Document) JobPostings() []JobPosting {
+	if !d.parsed.postings {
+		d.parsed.postings = true
+		d.postings = postingsFromItems(d.postingItems, d.URL)
+	}
+	return d.postings
+}
+
+// every ATS the page references, one per vendor/board
+func (d *Document) ATS() []ATSMatch {
+	if !d.parsed.ats {
+		d.parsed.ats = true
+		seen := make(map[string]bool)
+		for _, raw := range d.atsRefs {
+			href := resolveURL(d.URL, strings.TrimSpace(raw))
+			if href == "" {
+				continue
+			}
+			m, ok := MatchATS(href)
+			if !ok {
+				continue
+			}
+			key := m.Vendor + "|" + strings.ToLower(m.Board)
+			if !seen[key] {
+				seen[key] = true
+				d.ats = append(d.ats, m)
+			}
+		}
+	}
+	return d.ats
+}
@@ -0,0 +1,110 @@
+package web
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseDocument(t *testing.T) {
+	body := `<html lang="es-MX"><head><title>Empleos | Taqueria</title>
+		<meta name="description" content="Trabaja con nosotros">
+		<script type="application/ld+json">{"@type": "JobPosting", "title": "Cocinero"}</script>
+		<script>var hidden = "no aparece";</script></head>
+		<body><h1>Vacantes</h1><a href="/solicitud">Solicita aquí</a>
+		<iframe src="https://boards.greenhouse.io/embed/job_board?for=taqueria"></iframe></body></html>`
+	doc := ParseDocument("https://taqueria.com/empleos", body)
+
+	if doc.Lang != "es" || doc.Title != "Empleos | Taqueria" || doc.Description != "Trabaja con nosotros" {
+		t.Errorf("Unexpected metadata: lang %q, title %q, description %q", doc.Lang, doc.Title, doc.Description)
+	}
+	if strings.Contains(doc.Text, "no aparece") || !strings.Contains(doc.Text, "vacantes") {
+		t.Errorf("Expected visible text only, got %q", doc.Text)
+	}
+	if len(doc.anchors) != 1 || doc.anchors[0].URL != "https://taqueria.com/solicitud" || doc.anchors[0].Text != "Solicita aquí" {
+		t.Errorf("Unexpected anchors %+v", doc.anchors)
+	}
+	if postings := doc.JobPostings(); len(postings) != 1 || postings[0].Title != "Cocinero" {
+		t.Errorf("Unexpected postings %+v", postings)
+	}
+	if ats := doc.ATS(); len(ats) != 1 || ats[0].Vendor != ATSGreenhouse || ats[0].Board != "taqueria" {
+		t.Errorf("Unexpected ATS %+v", ats)
+	}
+	if idx := doc.tokenIndex()["vacante"]; len(idx) != 1 {
+		t.Errorf("Expected one indexed vacante, got %v", idx)
+	}
+}
+
+// a busy restaurant careers page: navigation, a few openings with markup, an apply form and a footer
+func benchmarkPage() string {
+	var sb strings.Builder
+	sb.WriteString(`<html lang="en"><head><title>Careers at Riverside Bistro</title>`)
+	sb.WriteString(`<script type="application/ld+json">[`)
+	for i := 0; i < 5; i++ {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		fmt.Fprintf(&sb, `{"@type": "JobPosting", "title": "Line Cook %d", "url": "/jobs/%d", "employmentType": "FULL_TIME"}`, i, i)
+	}
+	sb.WriteString(`]</script><style>body { color: #333 }</style></head><body><nav>`)
+	for i := 0; i < 40; i++ {
+		fmt.Fprintf(&sb, `<a href="/page-%d">Menu section %d</a>`, i, i)
+	}
+	sb.WriteString(`</nav><h1>Join our team</h1>`)
+	for i := 0; i < 30; i++ {
+		fmt.Fprintf(&sb, `<p>We are a family run restaurant serving the river district since 19%02d. Our kitchen and dining room crews work together every night.</p>`, i)
+	}
+	sb.WriteString(`<h2>Line Cook</h2><p>Full-time position, nights and weekends. Apply below, benefits included.</p>`)
+	sb.WriteString(`<form><input type="text" name="name"><input type="email" name="email"><input type="file" name="resume"><input type="submit" value="Apply now"></form>`)
+	sb.WriteString(`<footer><a href="/careers">Careers</a><a href="/about">About us</a></footer></body></html>`)
+	return sb.String()
+}
+
+// every detector parsing the body on its own, the way pages were analysed before Document
+func BenchmarkAnalyzeSeparately(b *testing.B) {
+	body := benchmarkPage()
+	pageURL := "https://riverside.example/careers"
+	q := TitlesQuery([]string{"line cook"})
+	p := DefaultProfile()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ExtractJobPostings(body, pageURL)
+		p.NegativePhrase(body)
+		p.IsJobPage(pageURL, body)
+		p.MatchesQuery(body, q)
+		p.Classify(pageURL, body, q)
+		DetectATS(body, pageURL)
+		ParseDocument(pageURL, body) // the crawler's links
+	}
+}
+
+// one Document shared by the detectors, as matchPage and the crawler use it
+func BenchmarkAnalyzeDocument(b *testing.B) {
+	body := benchmarkPage()
+	pageURL := "https://riverside.example/careers"
+	q := TitlesQuery([]string{"line cook"})
+	p := DefaultProfile()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		doc := ParseDocument(pageURL, body)
+		doc.JobPostings()
+		p.negativePhrase(doc)
+		p.isJobPage(doc)
+		p.matchesQuery(doc, q)
+		p.classify(doc, q)
+		doc.ATS()
+	}
+}
+
+func BenchmarkMatchesQueryLongPage(b *testing.B) {
+	doc := ParseDocument("https://riverside.example/careers", benchmarkPage())
+	q, err := ParseTitleQuery(`("line cook" OR chef OR dishwasher) -manager`)
+	if err != nil {
+		b.Fatal(err)
+	}
+	p := DefaultProfile()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p.matchesQuery(doc, q)
+	}
+}
@@ -0,0 +1,170 @@
+// per-domain memory: the careers page, ATS board and outcome of the last scrape of each business domain.
+// a later scrape tries the remembered page first and only runs the full discovery when it no longer validates.
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"cliscraper/internal/database"
+)
+
+// where domain records live between searches, FileDomainStore or database.DomainRepository
+type DomainStore interface {
+	// nil when the domain was never scraped
+	Get(ctx context.Context, domain string) (*database.DomainRecord, error)
+	Put(ctx context.Context, rec database.DomainRecord) error
+}
+
+var _ DomainStore = (*database.DomainRepository)(nil)
+
+// domain records in one JSON file, rewritten on every change
+type FileDomainStore struct {
+	path string
+
+	mu      sync.Mutex
+	records map[string]database.DomainRecord
+}
+
+func NewFileDomainStore(path string) *FileDomainStore {
+	return &FileDomainStore{path: path}
+}
+
+func (fs *FileDomainStore) Get(ctx context.Context, domain string) (*database.DomainRecord, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if err := fs.load(); err != nil {
+		return nil, err
+	}
+	rec, ok := fs.records[domain]
+	if !ok {
+		return nil, nil
+	}
+	return &rec, nil
+}
+
+func (fs *FileDomainStore) Put(ctx context.Context, rec database.DomainRecord) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if err := fs.load(); err != nil {
+		return err
+	}
+	fs.records[rec.Domain] = rec
+
+	data, err := json.MarshalIndent(fs.records, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(fs.path), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create domain store directory: %w", err)
+	}
+	// written aside and renamed, a crash mid-write leaves the old file intact
+	tmp := fs.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write domain store: %w", err)
+	}
+	return os.Rename(tmp, fs.path)
+}
+
+// read the file on first use, a missing file is an empty store
+func (fs *FileDomainStore) load() error {
+	if fs.records != nil {
+		return nil
+	}
+	records := make(map[string]database.DomainRecord)
+	data, err := os.ReadFile(fs.path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("failed to read domain store: %w", err)
+	default:
+		if err := json.Unmarshal(data, &records); err != nil {
+			return fmt.Errorf("invalid domain store %s: %w", fs.path, err)
+		}
+	}
+	fs.records = records
+	return nil
+}
+
+// key for the store, the host without www.
+func domainKey(rootURL string) string {
+	u, err := url.Parse(rootURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// what the store knows about the root's domain, nil without a store or a record
+func (s *Scraper) recall(ctx context.Context, rootURL string) *database.DomainRecord {
+	domain := domainKey(rootURL)
+	if s.Domains == nil || domain == "" {
+		return nil
+	}
+	rec, err := s.Domains.Get(ctx, domain)
+	if err != nil {
+		log.Printf("Reading domain record for %s failed: %v", domain, err)
+		return nil
+	}
+	return rec
+}
+
+// check the remembered careers page, then the remembered board. false sends the scrape through the full discovery
+func (s *Scraper) revisit(ctx context.Context, known *database.DomainRecord, q *TitleQuery) (Finding, bool) {
+	if known == nil {
+		return Finding{}, false
+	}
+	var boardATS []ATSMatch
+	if known.ATSVendor != "" && known.ATSBoard != "" {
+		boardATS = []ATSMatch{{Vendor: known.ATSVendor, Board: known.ATSBoard}}
+	}
+
+	var found Finding
+	if known.CareersURL != "" {
+		if page, err := s.fetchPage(ctx, known.CareersURL); err == nil {
+			if f, ok := s.matchPage(ParseDocument(page.URL, page.Body), q); ok {
+				found = f
+			}
+		}
+	}
+	found.ATS = mergeATS(found.ATS, boardATS)
+	if len(found.ATS) > 0 && ctx.Err() == nil {
+		s.boardPostings(ctx, &found, q)
+	}
+	if found.JobPage == "" {
+		return Finding{}, false
+	}
+	found.Remembered = true
+	return found, true
+}
+
+// store how the scrape went. a failed or blocked scrape keeps the careers page we knew, the site may be back next time
+func (s *Scraper) remember(ctx context.Context, rootURL string, known *database.DomainRecord, found Finding, err error) {
+	domain := domainKey(rootURL)
+	if s.Domains == nil || domain == "" || ctx.Err() != nil {
+		return
+	}
+	status := findingStatus(found, err)
+	rec := database.DomainRecord{Domain: domain, CheckedAt: time.Now(), Outcome: string(status)}
+	switch {
+	case found.JobPage != "":
+		rec.CareersURL = found.JobPage
+		if len(found.ATS) > 0 {
+			rec.ATSVendor, rec.ATSBoard = found.ATS[0].Vendor, found.ATS[0].Board
+		}
+	case known != nil && (status == StatusFailed || status == StatusBlocked):
+		rec.CareersURL, rec.ATSVendor, rec.ATSBoard = known.CareersURL, known.ATSVendor, known.ATSBoard
+	}
+	if err := s.Domains.Put(ctx, rec); err != nil {
+		log.Printf("Saving domain record for %s failed: %v", domain, err)
+	}
+}
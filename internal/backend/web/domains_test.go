@@ -0,0 +1,96 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"cliscraper/internal/database"
+)
+
+func TestFileDomainStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output", "domains.json")
+	ctx := context.Background()
+
+	store := NewFileDomainStore(path)
+	if rec, err := store.Get(ctx, "riverside.example"); rec != nil || err != nil {
+		t.Fatalf("Expected no record in a new store, got %+v %v", rec, err)
+	}
+	rec := database.DomainRecord{Domain: "riverside.example", CareersURL: "https://riverside.example/careers", Outcome: "found"}
+	if err := store.Put(ctx, rec); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	// a fresh store reads what the first one wrote
+	got, err := NewFileDomainStore(path).Get(ctx, "riverside.example")
+	if err != nil || got == nil || got.CareersURL != rec.CareersURL || got.Outcome != "found" {
+		t.Errorf("Expected %+v back, got %+v %v", rec, got, err)
+	}
+}
+
+func TestScrapeRemembersCareersPage(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		fetched []string
+		moved   bool
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fetched = append(fetched, r.Method+" "+r.URL.Path)
+		gone := moved
+		mu.Unlock()
+		switch {
+		case r.URL.Path == "/" && gone:
+			w.Write([]byte(`<html><body><a href="/work-with-us">Work with us</a></body></html>`))
+		case r.URL.Path == "/":
+			w.Write([]byte(`<html><body><a href="/about">About</a></body></html>`))
+		case r.URL.Path == "/about":
+			w.Write([]byte(`<html><body><a href="/careers">Careers</a></body></html>`))
+		case r.URL.Path == "/careers" && !gone, r.URL.Path == "/work-with-us" && gone:
+			w.Write([]byte(cookOpening))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	s := newTestScraper(srv)
+	s.Domains = NewFileDomainStore(filepath.Join(t.TempDir(), "domains.json"))
+	scrape := func() Finding {
+		t.Helper()
+		mu.Lock()
+		fetched = nil
+		mu.Unlock()
+		found, err := s.Scrape(context.Background(), srv.URL+"/", []string{"cook"})
+		if err != nil {
+			t.Fatalf("Scrape: %v", err)
+		}
+		return found
+	}
+
+	if found := scrape(); found.JobPage != srv.URL+"/careers" || found.Remembered {
+		t.Fatalf("Expected the careers page from the crawl, got %q (remembered %v)", found.JobPage, found.Remembered)
+	}
+
+	// straight to the remembered page, the homepage is not fetched
+	found := scrape()
+	if found.JobPage != srv.URL+"/careers" || !found.Remembered || strings.Join(fetched, ", ") != "GET /careers" {
+		t.Errorf("Expected the remembered careers page only, got %q (remembered %v) after %v", found.JobPage, found.Remembered, fetched)
+	}
+
+	// the page moved, the full discovery finds the new one and the store follows
+	mu.Lock()
+	moved = true
+	mu.Unlock()
+	if found := scrape(); found.JobPage != srv.URL+"/work-with-us" || found.Remembered {
+		t.Errorf("Expected the new careers page from the crawl, got %q (remembered %v)", found.JobPage, found.Remembered)
+	}
+	rec, _ := s.Domains.Get(context.Background(), domainKey(srv.URL))
+	if rec == nil || rec.CareersURL != srv.URL+"/work-with-us" || rec.Outcome != string(StatusFound) || rec.CheckedAt.IsZero() {
+		t.Errorf("Expected the new careers page remembered, got %+v", rec)
+	}
+}
@@ -0,0 +1,80 @@
+// failure categories for sites that could not be scraped, so a search can report why sites failed instead of a pile of error strings.
+package web
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"syscall"
+)
+
+// why a site could not be scraped, empty for sites that were
+type FailureKind string
+
+const (
+	FailureDNS      FailureKind = "dns"                // the host name does not resolve
+	FailureRefused  FailureKind = "connection_refused" // nothing listening on the port
+	FailureTLS      FailureKind = "tls"                // bad certificate or handshake
+	FailureTimeout  FailureKind = "timeout"            // no answer in time
+	FailureHTTP4xx  FailureKind = "http_4xx"
+	FailureHTTP5xx  FailureKind = "http_5xx"
+	FailureRobots   FailureKind = "robots_blocked"
+	FailureTooLarge FailureKind = "too_large"
+	FailureNotHTML  FailureKind = "not_html"
+	FailureOther    FailureKind = "other"
+)
+
+// a re-run can fix a flaky network or server, not a site that blocks us or serves no page
+func (k FailureKind) Retryable() bool {
+	switch k {
+	case FailureRobots, FailureNotHTML, FailureTooLarge:
+		return false
+	}
+	return k != ""
+}
+
+// the category of a scrape error, empty for nil
+func ClassifyFailure(err error) FailureKind {
+	var (
+		dnsErr     *net.DNSError
+		statusErr  *StatusError
+		netErr     net.Error
+		certErr    *tls.CertificateVerificationError
+		unknownCA  x509.UnknownAuthorityError
+		hostErr    x509.HostnameError
+		invalidErr x509.CertificateInvalidError
+		recordErr  tls.RecordHeaderError
+		alertErr   tls.AlertError
+	)
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrRobotsDisallowed):
+		return FailureRobots
+	case errors.Is(err, ErrTooLarge):
+		return FailureTooLarge
+	case errors.Is(err, ErrNotHTML):
+		return FailureNotHTML
+	case errors.As(err, &statusErr):
+		switch {
+		case statusErr.Code >= 400 && statusErr.Code <= 499:
+			return FailureHTTP4xx
+		case statusErr.Code >= 500 && statusErr.Code <= 599:
+			return FailureHTTP5xx
+		}
+		// a stray 3xx such as a 304 we had no cached copy for
+		return FailureOther
+	case errors.As(err, &dnsErr):
+		return FailureDNS
+	case errors.As(err, &certErr), errors.As(err, &unknownCA), errors.As(err, &hostErr),
+		errors.As(err, &invalidErr), errors.As(err, &recordErr), errors.As(err, &alertErr):
+		return FailureTLS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return FailureRefused
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return FailureTimeout
+	}
+	return FailureOther
+}
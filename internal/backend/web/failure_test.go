@@ -0,0 +1,64 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected FailureKind
+	}{
+		{name: "No error", err: nil, expected: ""},
+		{name: "Robots", err: robotsError("https://example.com/jobs"), expected: FailureRobots},
+		{name: "Too large", err: fmt.Errorf("x: %w", ErrTooLarge), expected: FailureTooLarge},
+		{name: "PDF", err: &ContentTypeError{URL: "https://example.com/a.pdf", ContentType: "application/pdf"}, expected: FailureNotHTML},
+		{name: "Not found", err: fmt.Errorf("failed to fetch: %w", &StatusError{Code: 404}), expected: FailureHTTP4xx},
+		{name: "Server error", err: &StatusError{Code: 503}, expected: FailureHTTP5xx},
+		{name: "Not modified", err: &StatusError{Code: 304}, expected: FailureOther},
+		{name: "DNS", err: &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "nope.invalid", IsNotFound: true}}, expected: FailureDNS},
+		{name: "Deadline", err: fmt.Errorf("failed to fetch: %w", context.DeadlineExceeded), expected: FailureTimeout},
+		{name: "Anything else", err: errors.New("connection reset by peer"), expected: FailureOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyFailure(tt.err); got != tt.expected {
+				t.Errorf("ClassifyFailure(%v) = %q, expected %q", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
+
+// real network errors, as the http client returns them
+func TestClassifyFailureFromClient(t *testing.T) {
+	// a port nobody listens on any more
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedURL := "http://" + ln.Addr().String() + "/"
+	ln.Close()
+
+	// self-signed certificate the default client does not trust
+	tlsSrv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tlsSrv.Close()
+
+	s := NewScraper("testbot")
+	s.Robots = nil
+	s.Client = &http.Client{}
+
+	for url, expected := range map[string]FailureKind{closedURL: FailureRefused, tlsSrv.URL + "/": FailureTLS} {
+		_, err := s.fetchBody(context.Background(), url)
+		if got := ClassifyFailure(err); got != expected {
+			t.Errorf("%s: expected %q, got %q (%v)", url, expected, got, err)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+// fetch layer: every page the scraper reads goes through fetchPage, which checks the status code, refuses
+// anything that is not html, caps the body size and decodes the declared charset to UTF-8.
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+// bodies past this are not read, a careers page is nowhere near it but a video or a scanned brochure can be
+const defaultMaxBodyBytes = 5 << 20
+
+var (
+	// returned (wrapped) for bodies over the size cap
+	ErrTooLarge = errors.New("response too large")
+	// returned (wrapped, see ContentTypeError) for pdfs, images and anything else that is not a web page
+	ErrNotHTML = errors.New("not an html page")
+)
+
+// a non-2xx response, its body is an error page rather than the page we asked for
+type StatusError struct {
+	URL  string
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s returned %d %s", e.URL, e.Code, http.StatusText(e.Code))
+}
+
+// a response that is not html, matches ErrNotHTML
+type ContentTypeError struct {
+	URL         string
+	ContentType string
+}
+
+func (e *ContentTypeError) Error() string {
+	return fmt.Sprintf("%s: %s is %s", e.URL, ErrNotHTML, e.ContentType)
+}
+
+func (e *ContentTypeError) Is(target error) bool { return target == ErrNotHTML }
+
+// pdfs are often printable applications or job descriptions, worth listing even though we cannot read them
+func (e *ContentTypeError) PDF() bool { return e.ContentType == "application/pdf" }
+
+// a fetched page and how we got there
+type fetchedPage struct {
+	// the url that answered, after redirects
+	URL string
+	// every url that redirected, the requested one first, empty when there were no redirects
+	Redirects []string
+	Body      string
+}
+
+func (s *Scraper) fetchBody(ctx context.Context, pageURL string) (string, error) {
+	page, err := s.fetchPage(ctx, pageURL)
+	return page.Body, err
+}
+
+// GET a page bound to ctx, so a cancelled search aborts the request mid-flight.
+// robots.txt is checked first and its crawl-delay honoured, then the limiter decides when the request may go out.
+// with a cache, a fresh copy is served without any request and a stale one is revalidated (see cache.go).
+// the page's URL and Redirects are filled in whenever a response came back, errors included
+func (s *Scraper) fetchPage(ctx context.Context, pageURL string) (fetchedPage, error) {
+	page := fetchedPage{URL: pageURL}
+	if err := s.allowed(ctx, pageURL); err != nil {
+		return page, err
+	}
+	cached := s.Cache.get(pageURL)
+	if cached != nil && s.Cache.fresh(cached) {
+		s.cacheStats.count(cacheHit)
+		return cached.page()
+	}
+
+	release, err := s.throttle(ctx, pageURL)
+	if err != nil {
+		return page, err
+	}
+	defer release()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return page, err
+	}
+	req.Header.Set("User-Agent", s.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.5")
+	cached.conditional(req)
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return page, err
+	}
+	defer resp.Body.Close()
+	page.URL, page.Redirects = redirectChain(resp)
+
+	if cached != nil && resp.StatusCode == http.StatusNotModified {
+		s.cacheStats.count(cacheRevalidated)
+		s.Cache.revalidated(cached, resp)
+		return cached.page()
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return page, &StatusError{URL: pageURL, Code: resp.StatusCode}
+	}
+	raw, err := readBody(resp, pageURL, s.maxBodyBytes())
+	if err != nil {
+		return page, err
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if page.Body, err = decodeHTML(pageURL, contentType, raw); err != nil {
+		return page, err
+	}
+	if s.Cache != nil {
+		s.cacheStats.count(cacheMiss)
+		s.Cache.put(pageURL, page, contentType, resp.Header, raw)
+	}
+	return page, nil
+}
+
+// politeness before any request: robots.txt and its crawl-delay, then the limiter. release once the response is read
+func (s *Scraper) wait(ctx context.Context, pageURL string) (func(), error) {
+	if err := s.allowed(ctx, pageURL); err != nil {
+		return nil, err
+	}
+	return s.throttle(ctx, pageURL)
+}
+
+// robots.txt lets us fetch pageURL
+func (s *Scraper) allowed(ctx context.Context, pageURL string) error {
+	if s.Robots == nil {
+		return nil
+	}
+	allowed, err := s.Robots.Allowed(ctx, pageURL)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return robotsError(pageURL)
+	}
+	return nil
+}
+
+// the host's crawl-delay, then the limiter
+func (s *Scraper) throttle(ctx context.Context, pageURL string) (func(), error) {
+	if s.Robots != nil {
+		if err := s.Robots.Wait(ctx, pageURL); err != nil {
+			return nil, err
+		}
+	}
+	if s.Limiter == nil {
+		return func() {}, nil
+	}
+	return s.Limiter.Wait(ctx, pageURL)
+}
+
+// the final url of resp and the urls that redirected to it, oldest first
+func redirectChain(resp *http.Response) (string, []string) {
+	final := resp.Request.URL.String()
+	var chain []string
+	for r := resp.Request.Response; r != nil; r = r.Request.Response {
+		chain = append([]string{r.Request.URL.String()}, chain...)
+	}
+	return final, chain
+}
+
+func (s *Scraper) maxBodyBytes() int64 {
+	if s.MaxBodyBytes <= 0 {
+		return defaultMaxBodyBytes
+	}
+	return s.MaxBodyBytes
+}
+
+// the raw body, up to limit bytes of something that may be html
+func readBody(resp *http.Response, pageURL string, limit int64) ([]byte, error) {
+	if resp.ContentLength > limit {
+		return nil, fmt.Errorf("%s: %w (%d bytes)", pageURL, ErrTooLarge, resp.ContentLength)
+	}
+	// a declared type that is clearly not a page is refused before reading anything
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "" && !isHTMLType(mediaType) {
+		return nil, &ContentTypeError{URL: pageURL, ContentType: mediaType}
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	if int64(len(raw)) > limit {
+		return nil, fmt.Errorf("%s: %w (over %d bytes)", pageURL, ErrTooLarge, limit)
+	}
+	return raw, nil
+}
+
+// the body as UTF-8 text
+func decodeHTML(pageURL, contentType string, raw []byte) (string, error) {
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "" {
+		// no Content-Type, go by the first bytes
+		sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(raw))
+		if !isHTMLType(sniffed) {
+			return "", &ContentTypeError{URL: pageURL, ContentType: sniffed}
+		}
+	}
+
+	// the header's charset, else a BOM or <meta charset>, else UTF-8 when the bytes allow it, windows-1252 otherwise
+	enc, name, _ := charset.DetermineEncoding(raw, contentType)
+	if name == "utf-8" {
+		return string(raw), nil
+	}
+	decoded, err := enc.NewDecoder().Bytes(raw)
+	if err != nil {
+		return "", fmt.Errorf("%s: decoding %s: %w", pageURL, name, err)
+	}
+	return string(decoded), nil
+}
+
+// html and xhtml, plus plain text which misconfigured servers send pages as
+func isHTMLType(mediaType string) bool {
+	switch strings.ToLower(mediaType) {
+	case "text/html", "application/xhtml+xml", "text/plain":
+		return true
+	}
+	return false
+}
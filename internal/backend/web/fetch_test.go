@@ -0,0 +1,94 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		status      int
+		body        string
+		expected    string
+		err         error
+	}{
+		{name: "Plain html", contentType: "text/html", body: "<html><body>Careers</body></html>", expected: "<html><body>Careers</body></html>"},
+		{name: "Latin-1 header", contentType: "text/html; charset=iso-8859-1", body: "<p>Se\xf1or cocinero</p>", expected: "<p>Señor cocinero</p>"},
+		{name: "Meta charset", contentType: "text/html", body: "<html><head><meta charset=\"windows-1252\"></head><body>Caf\xe9 team</body></html>", expected: "Café team"},
+		{name: "No content type", body: "<!DOCTYPE html><html><body>Jobs</body></html>", expected: "Jobs"},
+		{name: "PDF", contentType: "application/pdf", body: "%PDF-1.4", err: ErrNotHTML},
+		{name: "Sniffed image", body: "\x89PNG\r\n\x1a\n0000", err: ErrNotHTML},
+		{name: "Too large", contentType: "text/html", body: strings.Repeat("a", 2048), err: ErrTooLarge},
+		{name: "Not found", contentType: "text/html", status: http.StatusNotFound, body: "<h1>Jobs not found</h1>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// an empty value stops net/http from sniffing one itself
+				w.Header()["Content-Type"] = []string{tt.contentType}
+				if tt.contentType == "" {
+					w.Header()["Content-Type"] = nil
+				}
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+			s := newTestScraper(srv)
+			s.MaxBodyBytes = 1024
+
+			body, err := s.fetchBody(context.Background(), srv.URL+"/careers")
+			var statusErr *StatusError
+			switch {
+			case tt.status != 0:
+				if !errors.As(err, &statusErr) || statusErr.Code != tt.status {
+					t.Errorf("Expected a %d StatusError, got %v", tt.status, err)
+				}
+			case tt.err != nil:
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Expected %v, got %v", tt.err, err)
+				}
+			case err != nil:
+				t.Fatalf("fetchBody: %v", err)
+			case !strings.Contains(body, tt.expected):
+				t.Errorf("Expected body containing %q, got %q", tt.expected, body)
+			}
+		})
+	}
+}
+
+func TestCrawlNotesPDFs(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><body><a href="/files/employment-application.pdf">Printable application</a>
+				<a href="/files/menu.pdf">Menu</a><a href="/join-us">Join us</a></body></html>`))
+		case "/join-us":
+			// a careers link that serves a pdf
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte("%PDF-1.4"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	found, err := newTestScraper(srv).Scrape(context.Background(), srv.URL+"/", []string{"cook"})
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	expected := []string{srv.URL + "/files/employment-application.pdf", srv.URL + "/join-us"}
+	if strings.Join(found.Documents, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected documents %v, got %v", expected, found.Documents)
+	}
+}
@@ -0,0 +1,400 @@
+// structured job postings: schema.org JobPosting entries embedded as JSON-LD or microdata, which careers pages and most ATS boards publish for search engines.
+package web
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+
+	"cliscraper/internal/backend/ats"
+	"cliscraper/internal/database"
+)
+
+type JobPosting struct {
+	Title          string
+	Description    string
+	URL            string
+	DatePosted     *time.Time
+	ValidThrough   *time.Time
+	EmploymentType []string
+	BaseSalary     *database.Salary
+	// "Cincinnati, OH 45202", "Remote"...
+	JobLocation        []string
+	HiringOrganization string
+}
+
+// database record for the posting, locations are joined since a job has a single location field
+func (p JobPosting) Job() database.Job {
+	return database.Job{
+		Title:          p.Title,
+		Description:    p.Description,
+		Location:       strings.Join(p.JobLocation, "; "),
+		URL:            p.URL,
+		Company:        p.HiringOrganization,
+		EmploymentType: strings.Join(p.EmploymentType, ", "),
+		Salary:         p.BaseSalary,
+		ValidThrough:   p.ValidThrough,
+		PostedAt:       p.DatePosted,
+	}
+}
+
+// every JobPosting in the page's JSON-LD blocks and microdata, postings without a url point at pageURL
+func ExtractJobPostings(body, pageURL string) []JobPosting {
+	return ParseDocument(pageURL, body).JobPostings()
+}
+
+// postings from raw JobPosting objects, untitled ones dropped and duplicates kept once
+func postingsFromItems(items []map[string]interface{}, pageURL string) []JobPosting {
+	postings := make([]JobPosting, 0, len(items))
+	seen := make(map[string]bool)
+	for _, item := range items {
+		p := postingFromMap(item, pageURL)
+		if p.Title == "" {
+			continue
+		}
+		// pages often carry the same posting as both JSON-LD and microdata
+		key := strings.ToLower(p.Title) + "|" + p.URL
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		postings = append(postings, p)
+	}
+	return postings
+}
+
+// JobPosting objects in one JSON-LD block, which may be a single object, an array or an @graph
+func jsonLDPostings(raw string) []map[string]interface{} {
+	var data interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(raw)), &data); err != nil {
+		return nil
+	}
+
+	var found []map[string]interface{}
+	var walk func(interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case []interface{}:
+			for _, e := range v {
+				walk(e)
+			}
+		case map[string]interface{}:
+			if isJobPostingType(v["@type"]) {
+				found = append(found, v)
+				return
+			}
+			// @graph, ItemList elements, WebPage mainEntity...
+			for _, e := range v {
+				walk(e)
+			}
+		}
+	}
+	walk(data)
+	return found
+}
+
+func isJobPostingType(t interface{}) bool {
+	switch t := t.(type) {
+	case string:
+		// "JobPosting", "http://schema.org/JobPosting", "https://schema.org/JobPosting"
+		return t == "JobPosting" || strings.HasSuffix(t, "/JobPosting")
+	case []interface{}:
+		for _, e := range t {
+			if isJobPostingType(e) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func postingFromMap(m map[string]interface{}, pageURL string) JobPosting {
+	p := JobPosting{
+		Title:          strings.TrimSpace(html.UnescapeString(ldString(m["title"]))),
+		Description:    ats.PlainText(ldString(m["description"])),
+		URL:            ldString(m["url"]),
+		DatePosted:     ldTime(m["datePosted"]),
+		ValidThrough:   ldTime(m["validThrough"]),
+		EmploymentType: ldStrings(m["employmentType"]),
+		BaseSalary:     ldSalary(m["baseSalary"]),
+		JobLocation:    ldLocations(m["jobLocation"]),
+	}
+	if p.Title == "" {
+		p.Title = strings.TrimSpace(ldString(m["name"]))
+	}
+	if p.URL == "" {
+		p.URL = pageURL
+	} else {
+		p.URL = resolveURL(pageURL, p.URL)
+	}
+	if org := ldString(m["hiringOrganization"]); org != "" {
+		p.HiringOrganization = org
+	}
+	if strings.EqualFold(ldString(m["jobLocationType"]), "TELECOMMUTE") {
+		p.JobLocation = append(p.JobLocation, "Remote")
+	}
+	return p
+}
+
+// text of a JSON-LD value: strings as is, numbers formatted, objects by their name or @value
+func ldString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		if len(v) > 0 {
+			return ldString(v[0])
+		}
+	case map[string]interface{}:
+		for _, key := range []string{"name", "@value", "value"} {
+			if s := ldString(v[key]); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+func ldStrings(v interface{}) []string {
+	var out []string
+	switch v := v.(type) {
+	case []interface{}:
+		for _, e := range v {
+			out = append(out, ldStrings(e)...)
+		}
+	default:
+		// "FULL_TIME, PART_TIME" shows up too
+		for _, s := range strings.Split(ldString(v), ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+var ldTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"}
+
+func ldTime(v interface{}) *time.Time {
+	s := ldString(v)
+	if s == "" {
+		return nil
+	}
+	for _, layout := range ldTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+func ldFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(v), ",", ""), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// MonetaryAmount with a number or a QuantitativeValue (value or minValue/maxValue), or a bare number
+func ldSalary(v interface{}) *database.Salary {
+	if v == nil {
+		return nil
+	}
+	s := &database.Salary{}
+	amount, ok := v.(map[string]interface{})
+	if !ok {
+		if f, ok := ldFloat(v); ok {
+			s.Min, s.Max = f, f
+			return s
+		}
+		return nil
+	}
+
+	s.Currency = ldString(amount["currency"])
+	s.Unit = ldString(amount["unitText"])
+	value := amount["value"]
+	if q, ok := value.(map[string]interface{}); ok {
+		if u := ldString(q["unitText"]); u != "" {
+			s.Unit = u
+		}
+		value = q
+	}
+	if q, ok := value.(map[string]interface{}); ok {
+		s.Min, _ = ldFloat(q["minValue"])
+		s.Max, _ = ldFloat(q["maxValue"])
+		if f, ok := ldFloat(q["value"]); ok {
+			s.Min, s.Max = f, f
+		}
+	} else if f, ok := ldFloat(value); ok {
+		s.Min, s.Max = f, f
+	}
+
+	if s.Min == 0 && s.Max == 0 {
+		return nil
+	}
+	if s.Min == 0 {
+		s.Min = s.Max
+	}
+	if s.Max == 0 {
+		s.Max = s.Min
+	}
+	return s
+}
+
+// Place (or list of places) with a PostalAddress, or a plain string
+func ldLocations(v interface{}) []string {
+	switch v := v.(type) {
+	case []interface{}:
+		var out []string
+		for _, e := range v {
+			out = append(out, ldLocations(e)...)
+		}
+		return out
+	case string:
+		if v = strings.TrimSpace(v); v != "" {
+			return []string{v}
+		}
+	case map[string]interface{}:
+		addr, ok := v["address"].(map[string]interface{})
+		if !ok {
+			if s := ldString(v["address"]); s != "" {
+				return []string{s}
+			}
+			if s := ldString(v["name"]); s != "" {
+				return []string{s}
+			}
+			return nil
+		}
+		if loc := formatAddress(addr); loc != "" {
+			return []string{loc}
+		}
+	}
+	return nil
+}
+
+// "Mason, OH 45040", falling back to the street or country when that is all there is
+func formatAddress(addr map[string]interface{}) string {
+	var parts []string
+	if city := ldString(addr["addressLocality"]); city != "" {
+		parts = append(parts, city)
+	}
+	region := strings.TrimSpace(ldString(addr["addressRegion"]) + " " + ldString(addr["postalCode"]))
+	if region != "" {
+		parts = append(parts, region)
+	}
+	if len(parts) == 0 {
+		for _, key := range []string{"streetAddress", "addressCountry"} {
+			if s := ldString(addr[key]); s != "" {
+				return s
+			}
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// properties of a microdata item as a JSON-LD shaped map, nested itemscopes become nested maps
+func microdataItem(n *html.Node) map[string]interface{} {
+	item := map[string]interface{}{}
+	if t := attrValue(n, "itemtype"); t != "" {
+		item["@type"] = t
+	}
+
+	var walk func(*html.Node)
+	walk = func(c *html.Node) {
+		for ; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			_, scoped := attr(c, "itemscope")
+			if props := strings.Fields(attrValue(c, "itemprop")); len(props) > 0 {
+				var value interface{}
+				if scoped {
+					value = microdataItem(c)
+				} else {
+					value = microdataValue(c)
+				}
+				for _, prop := range props {
+					addProp(item, prop, value)
+				}
+			}
+			// a nested item owns everything below it
+			if !scoped {
+				walk(c.FirstChild)
+			}
+		}
+	}
+	walk(n.FirstChild)
+	return item
+}
+
+func addProp(item map[string]interface{}, prop string, value interface{}) {
+	switch existing := item[prop].(type) {
+	case nil:
+		item[prop] = value
+	case []interface{}:
+		item[prop] = append(existing, value)
+	default:
+		item[prop] = []interface{}{existing, value}
+	}
+}
+
+// value of a non-item property per the microdata spec
+func microdataValue(n *html.Node) string {
+	switch n.Data {
+	case "meta":
+		return attrValue(n, "content")
+	case "a", "link", "area":
+		return attrValue(n, "href")
+	case "img", "audio", "video", "source", "iframe", "embed":
+		return attrValue(n, "src")
+	case "time":
+		if dt := attrValue(n, "datetime"); dt != "" {
+			return dt
+		}
+	case "data", "meter":
+		return attrValue(n, "value")
+	}
+	if c := attrValue(n, "content"); c != "" {
+		return c
+	}
+	return strings.Join(strings.Fields(nodeText(n)), " ")
+}
+
+func attr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func attrValue(n *html.Node, key string) string {
+	v, _ := attr(n, key)
+	return v
+}
+
+// all text below n, script contents included
+func nodeText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return sb.String()
+}
@@ -0,0 +1,145 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const jsonLDPage = `<html><head>
+<script type="application/ld+json">
+{"@context":"https://schema.org","@graph":[
+	{"@type":"Organization","name":"Acme Diner"},
+	{"@type":"JobPosting","title":"Line Cook","description":"&lt;p&gt;Cook &lt;b&gt;breakfast&lt;/b&gt;&lt;/p&gt;",
+	 "datePosted":"2024-05-01","validThrough":"2024-06-01T00:00:00Z","employmentType":["FULL_TIME","PART_TIME"],
+	 "hiringOrganization":{"@type":"Organization","name":"Acme Diner"},
+	 "jobLocation":{"@type":"Place","address":{"@type":"PostalAddress","addressLocality":"Mason","addressRegion":"OH","postalCode":"45040"}},
+	 "baseSalary":{"@type":"MonetaryAmount","currency":"USD","value":{"@type":"QuantitativeValue","minValue":15,"maxValue":18.5,"unitText":"HOUR"}}}
+]}
+</script>
+<script type="application/ld+json">[{"@type":"JobPosting","title":"Dishwasher","url":"/jobs/dish","jobLocationType":"TELECOMMUTE","baseSalary":30000}]</script>
+<script type="application/ld+json">{not json</script>
+</head><body><h1>Join us</h1></body></html>`
+
+func TestExtractJobPostingsJSONLD(t *testing.T) {
+	postings := ExtractJobPostings(jsonLDPage, "https://acme.example/careers")
+	if len(postings) != 2 {
+		t.Fatalf("Expected 2 postings, got %d: %+v", len(postings), postings)
+	}
+
+	cook := postings[0]
+	if cook.Title != "Line Cook" || cook.Description != "Cook breakfast" {
+		t.Errorf("Unexpected title/description: %q / %q", cook.Title, cook.Description)
+	}
+	if cook.URL != "https://acme.example/careers" {
+		t.Errorf("Expected page url for posting without url, got %s", cook.URL)
+	}
+	if cook.DatePosted == nil || !cook.DatePosted.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected datePosted %v", cook.DatePosted)
+	}
+	if cook.ValidThrough == nil || cook.ValidThrough.Month() != time.June {
+		t.Errorf("Unexpected validThrough %v", cook.ValidThrough)
+	}
+	if len(cook.EmploymentType) != 2 || cook.EmploymentType[1] != "PART_TIME" {
+		t.Errorf("Unexpected employment type %v", cook.EmploymentType)
+	}
+	if cook.HiringOrganization != "Acme Diner" {
+		t.Errorf("Unexpected hiring organization %q", cook.HiringOrganization)
+	}
+	if len(cook.JobLocation) != 1 || cook.JobLocation[0] != "Mason, OH 45040" {
+		t.Errorf("Unexpected location %v", cook.JobLocation)
+	}
+	if s := cook.BaseSalary; s == nil || s.Currency != "USD" || s.Min != 15 || s.Max != 18.5 || s.Unit != "HOUR" {
+		t.Errorf("Unexpected salary %+v", cook.BaseSalary)
+	}
+
+	dish := postings[1]
+	if dish.URL != "https://acme.example/jobs/dish" {
+		t.Errorf("Expected resolved posting url, got %s", dish.URL)
+	}
+	if len(dish.JobLocation) != 1 || dish.JobLocation[0] != "Remote" {
+		t.Errorf("Expected remote location, got %v", dish.JobLocation)
+	}
+	if dish.BaseSalary == nil || dish.BaseSalary.Min != 30000 {
+		t.Errorf("Expected bare salary number, got %+v", dish.BaseSalary)
+	}
+
+	job := cook.Job()
+	if job.Location != "Mason, OH 45040" || job.EmploymentType != "FULL_TIME, PART_TIME" || job.Company != "Acme Diner" || job.PostedAt == nil {
+		t.Errorf("Unexpected job record %+v", job)
+	}
+}
+
+func TestExtractJobPostingsMicrodata(t *testing.T) {
+	body := `<html><body>
+	<div itemscope itemtype="https://schema.org/JobPosting">
+		<h2 itemprop="title">Server</h2>
+		<meta itemprop="datePosted" content="2024-04-02">
+		<span itemprop="employmentType">PART_TIME</span>
+		<div itemprop="hiringOrganization" itemscope itemtype="https://schema.org/Organization">
+			<span itemprop="name">Acme Diner</span>
+		</div>
+		<div itemprop="jobLocation" itemscope itemtype="https://schema.org/Place">
+			<div itemprop="address" itemscope itemtype="https://schema.org/PostalAddress">
+				<span itemprop="addressLocality">Cincinnati</span>, <span itemprop="addressRegion">OH</span>
+			</div>
+		</div>
+		<div itemprop="baseSalary" itemscope itemtype="https://schema.org/MonetaryAmount">
+			<meta itemprop="currency" content="USD">
+			<div itemprop="value" itemscope itemtype="https://schema.org/QuantitativeValue">
+				<meta itemprop="value" content="12.50"><meta itemprop="unitText" content="HOUR">
+			</div>
+		</div>
+		<div itemprop="description"><p>Take orders</p></div>
+	</div></body></html>`
+
+	postings := ExtractJobPostings(body, "https://acme.example/jobs")
+	if len(postings) != 1 {
+		t.Fatalf("Expected 1 posting, got %d", len(postings))
+	}
+	p := postings[0]
+	if p.Title != "Server" || p.HiringOrganization != "Acme Diner" || p.Description != "Take orders" {
+		t.Errorf("Unexpected posting %+v", p)
+	}
+	if len(p.JobLocation) != 1 || p.JobLocation[0] != "Cincinnati, OH" {
+		t.Errorf("Unexpected location %v", p.JobLocation)
+	}
+	if p.BaseSalary == nil || p.BaseSalary.Min != 12.5 || p.BaseSalary.Unit != "HOUR" {
+		t.Errorf("Unexpected salary %+v", p.BaseSalary)
+	}
+	if p.DatePosted == nil || p.DatePosted.Day() != 2 {
+		t.Errorf("Unexpected datePosted %v", p.DatePosted)
+	}
+}
+
+func TestScrapeUsesJobPostingMarkup(t *testing.T) {
+	// the visible text never names the role, only the JSON-LD does
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `<html><body><a href="/careers">Careers</a></body></html>`)
+		case "/careers":
+			fmt.Fprint(w, jsonLDPage)
+		}
+	}))
+	defer srv.Close()
+
+	s := NewScraper("testbot")
+	s.Client = srv.Client()
+	s.Robots = nil
+	s.ATS = nil
+
+	found, err := s.Scrape(context.Background(), srv.URL+"/", []string{"cook"})
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if found.JobPage != srv.URL+"/careers" {
+		t.Errorf("Expected careers page, got %q", found.JobPage)
+	}
+	if len(found.Jobs) != 1 || found.Jobs[0].Title != "Line Cook" {
+		t.Errorf("Expected the cook posting, got %+v", found.Jobs)
+	}
+}
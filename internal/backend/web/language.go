@@ -0,0 +1,98 @@
+// page language detection, so the detector can use the keywords and context words of the page's language.
+package web
+
+import (
+	"strings"
+	"unicode"
+)
+
+const DefaultLanguage = "en"
+
+type Language struct {
+	Code string
+	// common short words used to guess the language when the page does not declare it
+	stopwords []string
+}
+
+var Languages = map[string]Language{
+	"en": {Code: "en", stopwords: []string{"the", "and", "of", "to", "for", "with", "our", "we", "you", "is", "are", "your"}},
+	"es": {Code: "es", stopwords: []string{"el", "la", "los", "las", "de", "del", "que", "y", "en", "para", "con", "nuestro", "nosotros", "por", "una", "es"}},
+}
+
+// spanish counterparts of JobPageKeywords and contextWords for the default profile, accents folded
+var spanishKeywords = []string{
+	"empleo", "trabaja-con-nosotros", "trabaja con nosotros", "vacantes", "bolsa-de-trabajo", "bolsa de trabajo",
+	"unete", "carreras", "oportunidades", "contratando", "reclutamiento",
+}
+
+var spanishContextWords = []string{
+	"aplica", "aplicar", "solicita", "solicitud", "puesto", "vacante", "posicion", "requisitos", "responsabilidades",
+	"beneficios", "prestaciones", "contratando", "unete", "salario", "sueldo", "turno", "empleo",
+}
+
+// two letter code from <html lang="es-MX">, or a guess from stopwords in the visible text
+func DetectLanguage(body string) string {
+	return ParseDocument("", body).Lang
+}
+
+// count stopwords of each supported language, English wins ties and empty pages
+func guessLanguage(text string) string {
+	counts := make(map[string]int)
+	for _, w := range strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		for code, l := range Languages {
+			for _, sw := range l.stopwords {
+				if w == sw {
+					counts[code]++
+				}
+			}
+		}
+	}
+	best := DefaultLanguage
+	for code, n := range counts {
+		if n > counts[best] {
+			best = code
+		}
+	}
+	return best
+}
+
+// "es-MX" and "es_MX" give "es"
+func primarySubtag(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	return strings.SplitN(strings.SplitN(lang, "-", 2)[0], "_", 2)[0]
+}
+
+// latin accents dropped, so "Únete" and "unete" or "posición" and "posicion" compare equal
+func foldAccents(s string) string {
+	if isASCII(s) {
+		return s
+	}
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for _, r := range s {
+		if base, ok := accentFolds[r]; ok {
+			r = base
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+var accentFolds = map[rune]rune{
+	'á': 'a', 'à': 'a', 'â': 'a', 'ä': 'a', 'ã': 'a', 'å': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'ö': 'o', 'õ': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u',
+	'ñ': 'n', 'ç': 'c',
+	'Á': 'A', 'É': 'E', 'Í': 'I', 'Ó': 'O', 'Ú': 'U', 'Ü': 'U', 'Ñ': 'N', 'Ç': 'C',
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
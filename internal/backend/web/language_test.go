@@ -0,0 +1,89 @@
+package web
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{name: "Declared with region", body: `<html lang="es-MX"><body>Hello</body></html>`, expected: "es"},
+		{name: "Declared English", body: `<html lang="en"><body>Somos una empresa de la ciudad</body></html>`, expected: "en"},
+		{name: "Unsupported language", body: `<html lang="fr"><body>Bonjour</body></html>`, expected: DefaultLanguage},
+		{name: "Guessed from text", body: `<html><body><p>Somos una empresa familiar y buscamos personas para el equipo de la cocina</p></body></html>`, expected: "es"},
+		{name: "English text", body: `<html><body><p>We are a family business and we are looking for people to join the team</p></body></html>`, expected: "en"},
+		{name: "Nothing to go on", body: ``, expected: DefaultLanguage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectLanguage(tt.body); got != tt.expected {
+				t.Errorf("DetectLanguage(%q) = %q, expected %q", tt.body, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsJobPageSpanish(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		body     string
+		expected bool
+	}{
+		{name: "Spanish url", url: "https://taqueria.com/trabaja-con-nosotros", body: `<html lang="es"><body>Hola</body></html>`, expected: true},
+		{name: "Accented text", url: "https://taqueria.com/pagina", body: `<html lang="es"><body><h1>¡Únete a nuestro equipo!</h1></body></html>`, expected: true},
+		{name: "Vacantes", url: "https://taqueria.com/pagina", body: `<html lang="es"><body><h1>Vacantes</h1><p>Cocinero</p></body></html>`, expected: true},
+		{name: "Menu page", url: "https://taqueria.com/menu", body: `<html lang="es"><body><h1>Nuestro menú</h1><p>Tacos de pastor</p></body></html>`, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsJobPage(tt.url, tt.body); got != tt.expected {
+				t.Errorf("IsJobPage(%q) = %v, expected %v", tt.url, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMatchesJobTitleSpanish(t *testing.T) {
+	body := `<html lang="es"><body><h2>Técnico de Mantenimiento</h2><p>Turno nocturno, prestaciones de ley. Solicita hoy.</p></body></html>`
+	if !MatchesJobTitle(body, []string{"tecnico de mantenimiento"}) {
+		t.Error("Expected accented title with Spanish context words to match")
+	}
+	if !MatchesJobTitle(body, []string{"Técnico"}) {
+		t.Error("Expected accented query to match")
+	}
+	if MatchesJobTitle(`<html lang="es"><body><p>Nuestro técnico repara todo</p></body></html>`, []string{"tecnico"}) {
+		t.Error("Expected no match without context words")
+	}
+}
+
+func TestTitleTokensUnicode(t *testing.T) {
+	got := titleTokens("Técnico/a de Mantenimiento — Niños & Posiciones")
+	expected := []string{"tecnico", "a", "de", "mantenimiento", "nino", "posicion"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestCrawlFollowsSpanishCareersLink(t *testing.T) {
+	_, srv := newTestSite(t, map[string]string{
+		"/":         `<html lang="es"><body><a href="/menu">Menú</a><a href="/pagina-7">Trabaja con nosotros</a></body></html>`,
+		"/menu":     `<html lang="es"><body>Tacos</body></html>`,
+		"/pagina-7": `<html lang="es"><body><h1>Vacantes</h1><p>Buscamos cocinero de tiempo completo, solicita en tienda</p></body></html>`,
+	})
+
+	found, err := newTestScraper(srv).Scrape(context.Background(), srv.URL+"/", []string{"cocinero"})
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if found.JobPage != srv.URL+"/pagina-7" {
+		t.Errorf("Expected the Spanish careers page, got %q", found.JobPage)
+	}
+}
@@ -0,0 +1,156 @@
+// politeness limits for a scrape: caps concurrent requests per host, spaces requests to the same host apart and keeps the whole run under a requests-per-second budget.
+package web
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// zero values mean no limit
+type Limits struct {
+	// requests in flight to one host at a time
+	MaxPerHost int
+	// minimum time between two requests to the same host
+	HostInterval time.Duration
+	// requests per second across every host
+	RequestsPerSecond float64
+}
+
+// used by the server when a search does not ask for anything else
+var DefaultLimits = Limits{
+	MaxPerHost:        2,
+	HostInterval:      500 * time.Millisecond,
+	RequestsPerSecond: 20,
+}
+
+type hostSlot struct {
+	sem  chan struct{}
+	next time.Time
+}
+
+// enforces one set of Limits, safe for concurrent use by the worker pool
+type Limiter struct {
+	limits Limits
+	// limits shared with other runs, waited on after our own, see With
+	parent *Limiter
+
+	mu         sync.Mutex
+	hosts      map[string]*hostSlot
+	nextGlobal time.Time
+}
+
+func NewLimiter(limits Limits) *Limiter {
+	return &Limiter{
+		limits: limits,
+		hosts:  make(map[string]*hostSlot),
+	}
+}
+
+func (l *Limiter) Limits() Limits {
+	return l.limits
+}
+
+// a limiter for one run that applies limits on top of l. host slots and the global budget of l stay shared
+// with every other run, so concurrent searches together never go past them. a nil l only applies limits
+func (l *Limiter) With(limits Limits) *Limiter {
+	child := NewLimiter(limits)
+	child.parent = l
+	return child
+}
+
+// block until a request to pageURL may go out. release must be called once the response has been read
+func (l *Limiter) Wait(ctx context.Context, pageURL string) (release func(), err error) {
+	own, err := l.wait(ctx, pageURL)
+	if err != nil || l.parent == nil {
+		return own, err
+	}
+	// our own turn first, so a run queueing behind its own limits holds no shared slot meanwhile
+	shared, err := l.parent.Wait(ctx, pageURL)
+	if err != nil {
+		own()
+		return nil, err
+	}
+	return func() {
+		shared()
+		own()
+	}, nil
+}
+
+func (l *Limiter) wait(ctx context.Context, pageURL string) (release func(), err error) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, err
+	}
+	slot := l.slot(hostKey(u))
+
+	// per-host concurrency first, so a busy host does not eat into the global budget while it waits
+	release = func() {}
+	if slot.sem != nil {
+		select {
+		case slot.sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		release = func() { <-slot.sem }
+	}
+
+	// reserve start times under the lock, then sleep without it
+	l.mu.Lock()
+	now := time.Now()
+	at := now
+	if l.limits.HostInterval > 0 {
+		if slot.next.After(at) {
+			at = slot.next
+		}
+		slot.next = at.Add(l.limits.HostInterval)
+	}
+	if l.limits.RequestsPerSecond > 0 {
+		if l.nextGlobal.After(at) {
+			at = l.nextGlobal
+		}
+		l.nextGlobal = at.Add(time.Duration(float64(time.Second) / l.limits.RequestsPerSecond))
+	}
+	l.mu.Unlock()
+
+	if err := sleepCtx(ctx, at.Sub(now)); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+func (l *Limiter) slot(host string) *hostSlot {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	slot, ok := l.hosts[host]
+	if !ok {
+		slot = &hostSlot{}
+		if l.limits.MaxPerHost > 0 {
+			slot.sem = make(chan struct{}, l.limits.MaxPerHost)
+		}
+		l.hosts[host] = slot
+	}
+	return slot
+}
+
+// www.example.com and example.com are the same server as far as politeness goes
+func hostKey(u *url.URL) string {
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
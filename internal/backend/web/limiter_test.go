@@ -0,0 +1,150 @@
+package web
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimiterPerHostConcurrency(t *testing.T) {
+	l := NewLimiter(Limits{MaxPerHost: 2})
+
+	var inFlight, peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := l.Wait(context.Background(), "https://example.com/page")
+			if err != nil {
+				t.Errorf("Wait: %v", err)
+				return
+			}
+			n := inFlight.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			inFlight.Add(-1)
+			release()
+		}()
+	}
+	wg.Wait()
+
+	if p := peak.Load(); p > 2 {
+		t.Errorf("Expected at most 2 concurrent requests per host, got %d", p)
+	}
+}
+
+func TestLimiterHostInterval(t *testing.T) {
+	l := NewLimiter(Limits{HostInterval: 30 * time.Millisecond})
+
+	start := time.Now()
+	for _, u := range []string{"https://example.com/a", "https://www.example.com/b", "https://EXAMPLE.com/c"} {
+		release, err := l.Wait(context.Background(), u)
+		if err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+		release()
+	}
+	// www. and case differences are the same host, so three requests need two gaps
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("Expected requests to be spaced out, took only %v", elapsed)
+	}
+
+	// another host is not held up
+	start = time.Now()
+	release, err := l.Wait(context.Background(), "https://other.example.org/")
+	if err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	release()
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("Expected other host to go right away, waited %v", elapsed)
+	}
+}
+
+func TestLimiterGlobalRate(t *testing.T) {
+	l := NewLimiter(Limits{RequestsPerSecond: 50})
+
+	start := time.Now()
+	for _, u := range []string{"https://a.example/", "https://b.example/", "https://c.example/", "https://d.example/"} {
+		release, err := l.Wait(context.Background(), u)
+		if err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+		release()
+	}
+	// 4 requests at 50/s need at least 3 * 20ms
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("Expected global rate to be enforced, took only %v", elapsed)
+	}
+}
+
+func TestLimiterWaitCancelled(t *testing.T) {
+	l := NewLimiter(Limits{MaxPerHost: 1})
+	release, err := l.Wait(context.Background(), "https://example.com/")
+	if err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Wait(ctx, "https://example.com/other"); err != context.DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded while host is busy, got %v", err)
+	}
+}
+
+func TestLimiterWithSharesParentLimits(t *testing.T) {
+	shared := NewLimiter(Limits{MaxPerHost: 2})
+	// two runs that each allow 2 per host still only get 2 together
+	runs := []*Limiter{shared.With(Limits{MaxPerHost: 2}), shared.With(Limits{MaxPerHost: 2})}
+
+	var inFlight, peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(l *Limiter) {
+			defer wg.Done()
+			release, err := l.Wait(context.Background(), "https://example.com/page")
+			if err != nil {
+				t.Errorf("Wait: %v", err)
+				return
+			}
+			n := inFlight.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			inFlight.Add(-1)
+			release()
+		}(runs[i%2])
+	}
+	wg.Wait()
+
+	if p := peak.Load(); p > 2 {
+		t.Errorf("Expected at most 2 concurrent requests per host across runs, got %d", p)
+	}
+
+	// a run's own stricter limit still holds
+	strict := shared.With(Limits{MaxPerHost: 1})
+	release, err := strict.Wait(context.Background(), "https://example.com/")
+	if err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	defer release()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := strict.Wait(ctx, "https://example.com/other"); err != context.DeadlineExceeded {
+		t.Errorf("Expected the run's own cap of 1 to hold, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+// careers-path probing: when the crawl finds no careers link, the usual places (/careers, /jobs, careers.<domain>...) are tried directly.
+// each probe is a HEAD request, only urls that answer with a page are fetched and confirmed like any crawled page.
+package web
+
+import (
+	"context"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// the urls to probe for a site, paths on the root's host and subdomains of the root's domain, in profile order
+func (s *Scraper) probeURLs(root *url.URL, rootHost string) []string {
+	var urls []string
+	for _, probe := range s.profile().ProbePaths {
+		switch {
+		case strings.HasPrefix(probe, "/"):
+			urls = append(urls, root.Scheme+"://"+root.Host+probe)
+		case net.ParseIP(root.Hostname()) == nil:
+			// an ip has no subdomains
+			urls = append(urls, root.Scheme+"://"+probe+rootHost+"/")
+		}
+	}
+	return urls
+}
+
+// try the probe urls not seen by the crawl until one is confirmed as a job page. a careers page saying there are
+// no openings is kept while the rest are tried, like the crawl does. tried counts the probes sent
+func (s *Scraper) probe(ctx context.Context, root *url.URL, rootHost string, visited map[string]bool, q *TitleQuery) (found Finding, ok bool, tried int) {
+	var closed *Finding
+	for _, probeURL := range s.probeURLs(root, rootHost) {
+		key := crawlKey(probeURL)
+		if visited[key] || s.profile().SkipURL(probeURL) {
+			continue
+		}
+		visited[key] = true
+		if ctx.Err() != nil {
+			break
+		}
+
+		tried++
+		finalURL, exists := s.headPage(ctx, probeURL)
+		// a missing path often redirects to the home page we already looked at. a redirect to the probe url itself
+		// (/careers -> /careers/ or www.) has the same key and is still worth a look
+		if finalKey := crawlKey(finalURL); !exists || (finalKey != key && visited[finalKey]) {
+			continue
+		}
+		page, err := s.fetchPage(ctx, probeURL)
+		if err != nil {
+			continue
+		}
+		if f, matched := s.matchPage(ParseDocument(page.URL, page.Body), q); matched {
+			if f.NotHiring == "" {
+				return f, true, tried
+			}
+			if closed == nil {
+				closed = &f
+			}
+		}
+	}
+	if closed != nil {
+		return *closed, true, tried
+	}
+	return Finding{}, false, tried
+}
+
+// HEAD a probe url: where it ended up and whether it answered with something that could be a page.
+// servers that do not do HEAD get the benefit of the doubt, the GET will tell. with a cache the answer is kept for the TTL
+func (s *Scraper) headPage(ctx context.Context, pageURL string) (string, bool) {
+	if s.allowed(ctx, pageURL) != nil {
+		return pageURL, false
+	}
+	if e := s.Cache.result(headKey(pageURL)); e != nil {
+		s.cacheStats.count(cacheHit)
+		return e.FinalURL, headExists(e.Status, e.ContentType)
+	}
+	release, err := s.throttle(ctx, pageURL)
+	if err != nil {
+		return pageURL, false
+	}
+	defer release()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, pageURL, nil)
+	if err != nil {
+		return pageURL, false
+	}
+	req.Header.Set("User-Agent", s.UserAgent)
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		// careers.<domain> mostly does not exist, no need to look it up again
+		if s.Cache != nil && ClassifyFailure(err) == FailureDNS {
+			s.cacheStats.count(cacheMiss)
+			s.Cache.putResult(headKey(pageURL), 0, pageURL, http.Header{}, nil)
+		}
+		return pageURL, false
+	}
+	resp.Body.Close()
+	finalURL, _ := redirectChain(resp)
+	if s.Cache != nil {
+		s.cacheStats.count(cacheMiss)
+		s.Cache.putResult(headKey(pageURL), resp.StatusCode, finalURL, resp.Header, nil)
+	}
+	return finalURL, headExists(resp.StatusCode, resp.Header.Get("Content-Type"))
+}
+
+func headExists(status int, contentType string) bool {
+	switch {
+	case status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented:
+		return true
+	case status < 200 || status > 299:
+		return false
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "" || isHTMLType(mediaType)
+}
@@ -0,0 +1,112 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestScrapeProbesCareersPaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		noHead   bool
+		slash    bool
+		expected string
+		requests []string
+		probes   int
+	}{
+		{
+			name:     "Path",
+			expected: "/jobs",
+			// no sitemap either. /careers redirects home and is not fetched, /jobs answers the HEAD and is confirmed with a GET
+			requests: []string{"GET /", "GET /sitemap.xml", "HEAD /careers", "HEAD /", "HEAD /jobs", "GET /jobs"},
+			probes:   2,
+		},
+		{
+			name:     "No HEAD support",
+			noHead:   true,
+			expected: "/jobs",
+			requests: []string{"GET /", "GET /sitemap.xml", "HEAD /careers", "GET /careers", "HEAD /jobs", "GET /jobs"},
+			probes:   2,
+		},
+		{
+			name:     "Trailing slash redirect",
+			slash:    true,
+			expected: "/careers/",
+			requests: []string{"GET /", "GET /sitemap.xml", "HEAD /careers", "HEAD /careers/", "GET /careers", "GET /careers/"},
+			probes:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu       sync.Mutex
+				requests []string
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				requests = append(requests, r.Method+" "+r.URL.Path)
+				mu.Unlock()
+				if tt.noHead && r.Method == http.MethodHead {
+					w.WriteHeader(http.StatusMethodNotAllowed)
+					return
+				}
+				switch r.URL.Path {
+				case "/":
+					w.Write([]byte(`<html><body><p>Riverside Bistro, open daily</p></body></html>`))
+				case "/careers/":
+					if !tt.slash {
+						http.NotFound(w, r)
+						return
+					}
+					w.Write([]byte(cookOpening))
+				case "/careers":
+					if tt.slash {
+						http.Redirect(w, r, "/careers/", http.StatusMovedPermanently)
+						return
+					}
+					if tt.noHead {
+						http.NotFound(w, r)
+						return
+					}
+					http.Redirect(w, r, "/", http.StatusFound)
+				case "/jobs":
+					w.Write([]byte(cookOpening))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer srv.Close()
+
+			found, err := newTestScraper(srv).Scrape(context.Background(), srv.URL+"/", []string{"cook"})
+			if err != nil {
+				t.Fatalf("Scrape: %v", err)
+			}
+			if found.JobPage != srv.URL+tt.expected || !found.ProbeHit || found.Probes != tt.probes {
+				t.Errorf("Expected probed job page %s after %d probes, got %q (hit %v, %d probes)",
+					tt.expected, tt.probes, found.JobPage, found.ProbeHit, found.Probes)
+			}
+			if strings.Join(requests, ", ") != strings.Join(tt.requests, ", ") {
+				t.Errorf("Expected requests %v, got %v", tt.requests, requests)
+			}
+		})
+	}
+}
+
+func TestProbeURLs(t *testing.T) {
+	s := NewScraper("testbot")
+	s.Robots = nil
+	expected := []string{
+		"https://www.riverside.example/careers", "https://www.riverside.example/jobs", "https://www.riverside.example/employment",
+		"https://www.riverside.example/join-us", "https://careers.riverside.example/", "https://jobs.riverside.example/",
+	}
+	root, _ := url.Parse("https://www.riverside.example/")
+	if urls := s.probeURLs(root, "riverside.example"); strings.Join(urls, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected %v, got %v", expected, urls)
+	}
+}
@@ -0,0 +1,286 @@
+// detector profiles: the keywords, context words, context window, skipped urls, negative phrases and probe paths the detector works with.
+// the built-in default can be tuned or added to with JSON files loaded at startup, no rebuild needed.
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	DefaultProfileName   = "default"
+	defaultContextWindow = 8
+	maxContextWindow     = 50
+)
+
+type Profile struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// careers wording by language code, as it shows up in urls ("join-us") or page text. "en" applies to every page
+	Keywords map[string][]string `json:"keywords"`
+	// single words found around a real opening, by language code
+	ContextWords map[string][]string `json:"context_words"`
+	// words either side of a title searched for context words
+	ContextWindow int `json:"context_window"`
+	// pages whose url contains one of these are never fetched or accepted, e.g. "/blog/"
+	SkipURLPatterns []string `json:"skip_url_patterns"`
+	// phrases saying there are no openings, e.g. "no open positions". a careers page with one is reported as
+	// not_hiring and the crawl keeps looking for a page with real openings
+	NegativePhrases []string `json:"negative_phrases"`
+	// tried directly when the crawl finds no careers page: paths such as "/careers" and subdomains such as "careers."
+	ProbePaths []string `json:"probe_paths"`
+
+	once     sync.Once
+	contexts map[string]map[string]bool
+}
+
+var (
+	defaultProfileOnce sync.Once
+	defaultProfile     *Profile
+)
+
+// the compiled-in profile, used whenever a scraper or pool has none set
+func DefaultProfile() *Profile {
+	defaultProfileOnce.Do(func() {
+		defaultProfile = &Profile{
+			Name:        DefaultProfileName,
+			Description: "built-in English and Spanish keywords",
+			Keywords: map[string][]string{
+				"en": JobPageKeywords,
+				"es": spanishKeywords,
+			},
+			ContextWords: map[string][]string{
+				"en": contextWords,
+				"es": spanishContextWords,
+			},
+			ContextWindow:   defaultContextWindow,
+			SkipURLPatterns: []string{"/blog/", "/news/", "/press/", "/wp-content/", "/cart", "/checkout"},
+			NegativePhrases: []string{
+				"no open positions", "no current openings", "no openings at this time", "not currently hiring",
+				"not hiring right now", "not hiring at this time", "not hiring at the moment", "no open roles",
+				"no job openings", "no positions available", "no vacancies", "no hay vacantes",
+			},
+			ProbePaths: []string{"/careers", "/jobs", "/employment", "/join-us", "careers.", "jobs."},
+		}
+	})
+	return defaultProfile
+}
+
+// read one profile. fields left out keep the default profile's values, a language listed under keywords or
+// context_words replaces that language's list
+func LoadProfile(r io.Reader) (*Profile, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return decodeProfile(data)
+}
+
+func decodeProfile(data []byte) (*Profile, error) {
+	def := DefaultProfile()
+	p := &Profile{
+		Keywords:      copyLists(def.Keywords),
+		ContextWords:  copyLists(def.ContextWords),
+		ContextWindow: def.ContextWindow,
+		// copies, decoding into a slice reuses its array and would rewrite the default's
+		SkipURLPatterns: append([]string(nil), def.SkipURLPatterns...),
+		NegativePhrases: append([]string(nil), def.NegativePhrases...),
+		ProbePaths:      append([]string(nil), def.ProbePaths...),
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(p); err != nil {
+		return nil, fmt.Errorf("invalid detector profile: %w", err)
+	}
+
+	p.Name = strings.TrimSpace(p.Name)
+	switch {
+	case p.Name == "":
+		return nil, fmt.Errorf("invalid detector profile: missing name")
+	case p.ContextWindow < 1 || p.ContextWindow > maxContextWindow:
+		return nil, fmt.Errorf("invalid detector profile %s: context_window must be 1-%d", p.Name, maxContextWindow)
+	case len(p.Keywords[DefaultLanguage]) == 0:
+		return nil, fmt.Errorf("invalid detector profile %s: no %s keywords", p.Name, DefaultLanguage)
+	}
+
+	// matching is done on lowercase, accent folded text
+	for lang, kws := range p.Keywords {
+		p.Keywords[lang] = normalizeList(kws)
+	}
+	for lang, words := range p.ContextWords {
+		p.ContextWords[lang] = normalizeList(words)
+	}
+	p.SkipURLPatterns = normalizeList(p.SkipURLPatterns)
+	p.NegativePhrases = normalizeList(p.NegativePhrases)
+	p.ProbePaths = normalizeList(p.ProbePaths)
+	for _, probe := range p.ProbePaths {
+		if !strings.HasPrefix(probe, "/") && !strings.HasSuffix(probe, ".") {
+			return nil, fmt.Errorf("invalid detector profile %s: probe path %q must start with / or end with .", p.Name, probe)
+		}
+	}
+	return p, nil
+}
+
+// the careers keywords for a page in lang, English included
+func (p *Profile) keywordsFor(lang string) []string {
+	kws := p.Keywords[DefaultLanguage]
+	if lang != DefaultLanguage && len(p.Keywords[lang]) > 0 {
+		kws = append(append([]string{}, kws...), p.Keywords[lang]...)
+	}
+	return kws
+}
+
+// keywords of every language, for urls and link text before we know what a page is written in
+func (p *Profile) allKeywords() []string {
+	var kws []string
+	for _, lang := range sortedKeys(p.Keywords) {
+		kws = append(kws, p.Keywords[lang]...)
+	}
+	return kws
+}
+
+// stemmed context words for a page in lang, English included
+func (p *Profile) contextSet(lang string) map[string]bool {
+	p.once.Do(func() {
+		p.contexts = make(map[string]map[string]bool)
+		for code, words := range p.ContextWords {
+			set := make(map[string]bool, len(words))
+			for _, w := range words {
+				set[stem(foldAccents(w))] = true
+			}
+			p.contexts[code] = set
+		}
+	})
+
+	en := p.contexts[DefaultLanguage]
+	other, ok := p.contexts[lang]
+	if lang == DefaultLanguage || !ok {
+		return en
+	}
+	set := make(map[string]bool, len(en)+len(other))
+	for w := range en {
+		set[w] = true
+	}
+	for w := range other {
+		set[w] = true
+	}
+	return set
+}
+
+func (p *Profile) window() int {
+	if p.ContextWindow <= 0 {
+		return defaultContextWindow
+	}
+	return p.ContextWindow
+}
+
+// the url matches one of the skip patterns
+func (p *Profile) SkipURL(pageURL string) bool {
+	u := strings.ToLower(pageURL)
+	for _, pattern := range p.SkipURLPatterns {
+		if strings.Contains(u, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// the first negative phrase in the page's visible text, empty when there is none
+func (p *Profile) NegativePhrase(body string) string {
+	return p.negativePhrase(ParseDocument("", body))
+}
+
+func (p *Profile) negativePhrase(doc *Document) string {
+	text := strings.Join(strings.Fields(doc.foldedText()), " ")
+	// "we’re not hiring" reads the same as "we're not hiring"
+	text = strings.ReplaceAll(text, "’", "'")
+	for _, phrase := range p.NegativePhrases {
+		if strings.Contains(text, phrase) {
+			return phrase
+		}
+	}
+	return ""
+}
+
+// profiles by name, always holding the default
+type Profiles map[string]*Profile
+
+// the default plus every profile in path, a JSON file or a directory of *.json files.
+// a file named after an existing profile replaces it, so "default" can be retuned too
+func LoadProfiles(path string) (Profiles, error) {
+	profiles := Profiles{DefaultProfileName: DefaultProfile()}
+	if path == "" {
+		return profiles, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		if files, err = filepath.Glob(filepath.Join(path, "*.json")); err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		p, err := decodeProfile(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		profiles[p.Name] = p
+	}
+	return profiles, nil
+}
+
+// profile names in order, default first
+func (ps Profiles) Names() []string {
+	names := make([]string, 0, len(ps))
+	for name := range ps {
+		if name != DefaultProfileName {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfileName}, names...)
+}
+
+func normalizeList(list []string) []string {
+	out := make([]string, 0, len(list))
+	for _, s := range list {
+		if s = foldAccents(strings.ToLower(strings.TrimSpace(s))); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func copyLists(m map[string][]string) map[string][]string {
+	out := make(map[string][]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,134 @@
+package web
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadProfileKeepsDefaultsForMissingFields(t *testing.T) {
+	p, err := LoadProfile(strings.NewReader(`{"name": "kitchens", "context_window": 3, "keywords": {"es": ["Cocina-Empleos"]}}`))
+	if err != nil {
+		t.Fatalf("LoadProfile: %v", err)
+	}
+	if p.ContextWindow != 3 {
+		t.Errorf("Expected context window 3, got %d", p.ContextWindow)
+	}
+	if len(p.Keywords["en"]) != len(JobPageKeywords) {
+		t.Errorf("Expected default en keywords, got %v", p.Keywords["en"])
+	}
+	if len(p.Keywords["es"]) != 1 || p.Keywords["es"][0] != "cocina-empleos" {
+		t.Errorf("Expected es keywords to be replaced and lowercased, got %v", p.Keywords["es"])
+	}
+	if len(p.NegativePhrases) == 0 || len(p.SkipURLPatterns) == 0 {
+		t.Error("Expected default negative phrases and skip patterns")
+	}
+	if len(DefaultProfile().Keywords["es"]) == 1 {
+		t.Error("Loading a profile must not change the default profile")
+	}
+}
+
+func TestLoadProfileErrors(t *testing.T) {
+	tests := map[string]string{
+		"Missing name":     `{"context_window": 4}`,
+		"Window too large": `{"name": "wide", "context_window": 500}`,
+		"No en keywords":   `{"name": "empty", "keywords": {"en": []}}`,
+		"Unknown field":    `{"name": "typo", "keyword": {"en": ["jobs"]}}`,
+		"Not JSON":         `name: yaml`,
+		"Bad probe path":   `{"name": "probes", "probe_paths": ["careers"]}`,
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadProfile(strings.NewReader(input)); err == nil {
+				t.Errorf("Expected an error for %s", input)
+			}
+		})
+	}
+}
+
+func TestLoadProfilesFromDirectory(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "strict.json"), []byte(`{"name": "strict", "context_window": 2}`), 0o644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte(`ignored`), 0o644)
+
+	profiles, err := LoadProfiles(dir)
+	if err != nil {
+		t.Fatalf("LoadProfiles: %v", err)
+	}
+	names := profiles.Names()
+	if len(names) != 2 || names[0] != DefaultProfileName || names[1] != "strict" {
+		t.Errorf("Expected [default strict], got %v", names)
+	}
+
+	if _, err := LoadProfiles(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Expected an error for a missing path")
+	}
+}
+
+func TestLoadProfilesBundledExample(t *testing.T) {
+	profiles, err := LoadProfiles("../../../profiles")
+	if err != nil {
+		t.Fatalf("LoadProfiles: %v", err)
+	}
+	if _, ok := profiles["healthcare"]; !ok {
+		t.Errorf("Expected the healthcare example profile, got %v", profiles.Names())
+	}
+}
+
+func TestProfileChangesDetection(t *testing.T) {
+	body := `<html><body><h2>Dental Assistant</h2><p>growing office, please apply</p></body></html>`
+	narrow, err := LoadProfile(strings.NewReader(`{"name": "narrow", "context_window": 2}`))
+	if err != nil {
+		t.Fatalf("LoadProfile: %v", err)
+	}
+	q := TitlesQuery([]string{"dental assistant"})
+	if !DefaultProfile().MatchesQuery(body, q) {
+		t.Error("Expected a match within the default window")
+	}
+	if narrow.MatchesQuery(body, q) {
+		t.Error("Expected no match with a two word window")
+	}
+
+	if !DefaultProfile().SkipURL("https://example.com/blog/we-are-hiring") {
+		t.Error("Expected blog posts to be skipped")
+	}
+	if phrase := DefaultProfile().NegativePhrase(`<html><body><h1>Careers</h1><p>We have no   open positions right now.</p></body></html>`); phrase != "no open positions" {
+		t.Errorf("Expected negative phrase to be found, got %q", phrase)
+	}
+}
+
+func TestScrapeReportsNotHiringPage(t *testing.T) {
+	_, srv := newTestSite(t, map[string]string{
+		"/":        `<html><body><a href="/careers">Careers</a></body></html>`,
+		"/careers": `<html><body><h1>Careers</h1><p>We’re not hiring right now, check back later</p></body></html>`,
+	})
+
+	found, err := newTestScraper(srv).Scrape(context.Background(), srv.URL+"/", []string{"cook"})
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if found.JobPage != srv.URL+"/careers" || found.NotHiring != "not hiring right now" {
+		t.Errorf("Expected the careers page flagged as not hiring, got %q (%q)", found.JobPage, found.NotHiring)
+	}
+	if last := found.Reasons[len(found.Reasons)-1]; !strings.HasPrefix(last, SignalNegativePhrase) {
+		t.Errorf("Expected a negative_phrase reason, got %v", found.Reasons)
+	}
+}
+
+func TestScrapePrefersOpeningsOverNotHiringPage(t *testing.T) {
+	_, srv := newTestSite(t, map[string]string{
+		"/":        `<html><body><a href="/careers">Careers</a><a href="/jobs">Jobs</a></body></html>`,
+		"/careers": `<html><body><h1>Careers</h1><p>There are no open positions at this time</p></body></html>`,
+		"/jobs":    `<html><body><h1>Jobs</h1><h2>Line Cook</h2><p>Full-time, apply in person</p></body></html>`,
+	})
+
+	found, err := newTestScraper(srv).Scrape(context.Background(), srv.URL+"/", []string{"line cook"})
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if found.JobPage != srv.URL+"/jobs" || found.NotHiring != "" {
+		t.Errorf("Expected the page with openings, got %q (%q)", found.JobPage, found.NotHiring)
+	}
+}
@@ -0,0 +1,380 @@
+// boolean title queries such as `"line cook" OR chef -sous`: words and quoted phrases, OR, -exclusions and ( ) grouping.
+// adjacent terms must all match, OR binds tighter. parsed once per search, then evaluated against every page the scraper looks at.
+package web
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidQuery = errors.New("invalid title query")
+
+// parsed query, a nil *TitleQuery matches everything
+type TitleQuery struct {
+	raw  string
+	root queryNode
+}
+
+// page words and the context words of its language, a nil context needs no hiring words near the terms
+type queryText struct {
+	words []string
+	// positions of each word, nil for short texts that are simply scanned
+	index   map[string][]int
+	context map[string]bool
+	window  int
+}
+
+// start of every occurrence of phrase in the words
+func (t queryText) phraseIndexes(phrase []string) []int {
+	if t.index == nil || len(phrase) == 0 {
+		return phraseIndexes(t.words, phrase)
+	}
+	var found []int
+	for _, i := range t.index[phrase[0]] {
+		if i+len(phrase) <= len(t.words) && equalWords(t.words[i:i+len(phrase)], phrase) {
+			found = append(found, i)
+		}
+	}
+	return found
+}
+
+func equalWords(a, b []string) bool {
+	for i := range b {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type queryNode interface {
+	eval(t queryText) bool
+	// positive terms, for reporting what matched
+	terms(out []string) []string
+}
+
+type termNode struct {
+	raw     string
+	phrases [][]string // the term and its synonyms
+}
+
+type notNode struct{ child queryNode }
+type andNode []queryNode
+type orNode []queryNode
+
+func (n termNode) eval(t queryText) bool {
+	for _, phrase := range n.phrases {
+		for _, i := range t.phraseIndexes(phrase) {
+			if t.context == nil || hasContextAround(t.words, i, len(phrase), t.context, t.window) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// an excluded word anywhere on the page rules it out, context or not
+func (n notNode) eval(t queryText) bool {
+	return !n.child.eval(queryText{words: t.words, index: t.index})
+}
+
+func (n andNode) eval(t queryText) bool {
+	for _, c := range n {
+		if !c.eval(t) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n orNode) eval(t queryText) bool {
+	for _, c := range n {
+		if c.eval(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n termNode) terms(out []string) []string { return append(out, n.raw) }
+func (n notNode) terms(out []string) []string  { return out }
+
+func (n andNode) terms(out []string) []string {
+	for _, c := range n {
+		out = c.terms(out)
+	}
+	return out
+}
+
+func (n orNode) terms(out []string) []string {
+	for _, c := range n {
+		out = c.terms(out)
+	}
+	return out
+}
+
+// parse a title query. a blank query gives nil, which matches every page
+func ParseTitleQuery(s string) (*TitleQuery, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	toks, err := lexQuery(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{toks: toks}
+	root, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidQuery, p.toks[p.pos].text)
+	}
+	if len(root.terms(nil)) == 0 {
+		return nil, fmt.Errorf("%w: nothing to search for, only exclusions", ErrInvalidQuery)
+	}
+	return &TitleQuery{raw: s, root: root}, nil
+}
+
+// OR of the titles, each one a phrase, the way searches used to take a list of titles
+func TitlesQuery(titles []string) *TitleQuery {
+	var or orNode
+	var raw []string
+	for _, t := range titles {
+		if strings.TrimSpace(t) == "" {
+			continue
+		}
+		or = append(or, newTerm(t))
+		raw = append(raw, fmt.Sprintf("%q", strings.TrimSpace(t)))
+	}
+	if len(or) == 0 {
+		return nil
+	}
+	return &TitleQuery{raw: strings.Join(raw, " OR "), root: or}
+}
+
+func (q *TitleQuery) String() string {
+	if q == nil {
+		return ""
+	}
+	return q.raw
+}
+
+// the words and phrases the query looks for, exclusions left out
+func (q *TitleQuery) Terms() []string {
+	if q == nil {
+		return nil
+	}
+	return q.root.terms(nil)
+}
+
+// the page's visible text satisfies the query, with hiring words of the page's language near the matched terms
+func (q *TitleQuery) MatchesPage(body string) bool {
+	return DefaultProfile().MatchesQuery(body, q)
+}
+
+// MatchesPage with this profile's context words and window
+func (p *Profile) MatchesQuery(body string, q *TitleQuery) bool {
+	return p.matchesQuery(ParseDocument("", body), q)
+}
+
+func (p *Profile) matchesQuery(doc *Document, q *TitleQuery) bool {
+	if q == nil {
+		return true
+	}
+	return q.root.eval(queryText{
+		words:   doc.Tokens(),
+		index:   doc.tokenIndex(),
+		context: p.contextSet(doc.Lang),
+		window:  p.window(),
+	})
+}
+
+// short text such as a posting title satisfies the query, no context needed
+func (q *TitleQuery) MatchesText(text string) bool {
+	if q == nil {
+		return true
+	}
+	return q.root.eval(queryText{words: titleTokens(text)})
+}
+
+func newTerm(raw string) termNode {
+	return termNode{raw: raw, phrases: defaultSynonyms().Expand(raw)}
+}
+
+// a term as written, for exclusions: -manager rules out managers, not supervisors
+func literalTerm(raw string) termNode {
+	return termNode{raw: raw, phrases: [][]string{titleTokens(raw)}}
+}
+
+type queryTokKind int
+
+const (
+	tokTerm queryTokKind = iota
+	tokPhrase
+	tokOr
+	tokNot
+	tokOpen
+	tokClose
+)
+
+type queryTok struct {
+	kind queryTokKind
+	text string
+}
+
+func lexQuery(s string) ([]queryTok, error) {
+	var toks []queryTok
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(':
+			toks = append(toks, queryTok{tokOpen, "("})
+			i++
+		case c == ')':
+			toks = append(toks, queryTok{tokClose, ")"})
+			i++
+		case c == '|':
+			toks = append(toks, queryTok{tokOr, "|"})
+			i++
+		case c == '-' && (i == 0 || strings.ContainsRune(" \t(", rune(s[i-1]))):
+			// only a leading dash excludes, "part-time" is a word
+			toks = append(toks, queryTok{tokNot, "-"})
+			i++
+		case c == '"':
+			end := strings.IndexByte(s[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated quote", ErrInvalidQuery)
+			}
+			phrase := strings.TrimSpace(s[i+1 : i+1+end])
+			if len(titleTokens(phrase)) == 0 {
+				return nil, fmt.Errorf("%w: empty phrase", ErrInvalidQuery)
+			}
+			toks = append(toks, queryTok{tokPhrase, phrase})
+			i += end + 2
+		default:
+			j := i
+			for j < len(s) && !strings.ContainsRune(" \t()\"|", rune(s[j])) {
+				j++
+			}
+			word := s[i:j]
+			i = j
+			if word == "OR" {
+				toks = append(toks, queryTok{tokOr, word})
+				continue
+			}
+			// punctuation only ("&", "/") carries nothing to match on
+			if len(titleTokens(word)) > 0 {
+				toks = append(toks, queryTok{tokTerm, word})
+			}
+		}
+	}
+	return toks, nil
+}
+
+// OR binds tighter than the implicit AND, so `cook OR chef -sous` excludes sous from both
+// and := or+   or := unary ("OR" unary)*   unary := "-" unary | "(" and ")" | term | phrase
+type queryParser struct {
+	toks []queryTok
+	pos  int
+	// inside an exclusion, terms are not expanded to their synonyms
+	excluding bool
+}
+
+func (p *queryParser) peek() (queryTok, bool) {
+	if p.pos >= len(p.toks) {
+		return queryTok{}, false
+	}
+	return p.toks[p.pos], true
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	var and andNode
+	for {
+		tok, ok := p.peek()
+		if !ok || tok.kind == tokClose {
+			break
+		}
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, n)
+	}
+	switch len(and) {
+	case 0:
+		if tok, ok := p.peek(); ok {
+			return nil, fmt.Errorf("%w: expected a term before %q", ErrInvalidQuery, tok.text)
+		}
+		return nil, fmt.Errorf("%w: expected a term", ErrInvalidQuery)
+	case 1:
+		return and[0], nil
+	}
+	return and, nil
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	or := orNode{first}
+	for {
+		tok, ok := p.peek()
+		if !ok || tok.kind != tokOr {
+			break
+		}
+		p.pos++
+		next, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, next)
+	}
+	if len(or) == 1 {
+		return first, nil
+	}
+	return or, nil
+}
+
+func (p *queryParser) parseUnary() (queryNode, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("%w: expected a term at the end", ErrInvalidQuery)
+	}
+	p.pos++
+	switch tok.kind {
+	case tokNot:
+		if next, ok := p.peek(); !ok || next.kind == tokNot {
+			return nil, fmt.Errorf("%w: \"-\" must be followed by a term", ErrInvalidQuery)
+		}
+		excluding := p.excluding
+		p.excluding = true
+		child, err := p.parseUnary()
+		p.excluding = excluding
+		if err != nil {
+			return nil, err
+		}
+		return notNode{child}, nil
+	case tokOpen:
+		inner, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if next, ok := p.peek(); !ok || next.kind != tokClose {
+			return nil, fmt.Errorf("%w: missing \")\"", ErrInvalidQuery)
+		}
+		p.pos++
+		return inner, nil
+	case tokTerm, tokPhrase:
+		if p.excluding {
+			return literalTerm(tok.text), nil
+		}
+		return newTerm(tok.text), nil
+	}
+	return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidQuery, tok.text)
+}
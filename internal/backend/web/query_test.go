@@ -0,0 +1,127 @@
+package web
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseTitleQueryMatchesPage(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		body     string
+		expected bool
+	}{
+		{
+			name:     "Quoted phrase",
+			query:    `"line cook"`,
+			body:     "<html><body><h2>Line Cook</h2><p>Apply now</p></body></html>",
+			expected: true,
+		},
+		{
+			name:     "Either side of OR",
+			query:    `"line cook" OR chef`,
+			body:     "<html><body><h2>Executive Chef</h2><p>We are hiring</p></body></html>",
+			expected: true,
+		},
+		{
+			name:     "Exclusion rules the page out",
+			query:    `"line cook" OR chef -sous`,
+			body:     "<html><body><h2>Sous Chef</h2><p>We are hiring</p></body></html>",
+			expected: false,
+		},
+		{
+			name:     "Exclusions are not expanded to synonyms",
+			query:    "cook -manager",
+			body:     "<html><body><h2>Line Cook</h2><p>Now hiring, report to the kitchen supervisor</p></body></html>",
+			expected: true,
+		},
+		{
+			name:     "Excluded groups are taken literally too",
+			query:    "technician -(engineer OR manager)",
+			body:     "<html><body><h2>IT Technician</h2><p>Now hiring, you will support our developer team</p></body></html>",
+			expected: true,
+		},
+		{
+			name:     "Adjacent terms all have to match",
+			query:    "barista manager",
+			body:     "<html><body><h2>Barista</h2><p>Apply now</p></body></html>",
+			expected: false,
+		},
+		{
+			name:     "Grouping",
+			query:    "(barista OR cashier) -manager",
+			body:     "<html><body><h2>Cashier</h2><p>Part-time position, apply now</p></body></html>",
+			expected: true,
+		},
+		{
+			name:     "Terms still need hiring context",
+			query:    "chef",
+			body:     "<html><body><p>Our chef makes everything fresh</p></body></html>",
+			expected: false,
+		},
+		{
+			name:     "Hyphenated words are not exclusions",
+			query:    "part-time cashier",
+			body:     "<html><body><h2>Part-time Cashier</h2><p>Apply now</p></body></html>",
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := ParseTitleQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseTitleQuery(%q): %v", tt.query, err)
+			}
+			if got := q.MatchesPage(tt.body); got != tt.expected {
+				t.Errorf("Query %q on %q = %v, expected %v", tt.query, tt.body, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseTitleQueryErrors(t *testing.T) {
+	tests := []string{
+		`"line cook`,
+		"(cook OR chef",
+		"cook)",
+		"cook OR",
+		"OR chef",
+		"()",
+		"cook -",
+		"-sous",
+		`""`,
+	}
+
+	for _, query := range tests {
+		t.Run(query, func(t *testing.T) {
+			if _, err := ParseTitleQuery(query); !errors.Is(err, ErrInvalidQuery) {
+				t.Errorf("Expected ErrInvalidQuery for %q, got %v", query, err)
+			}
+		})
+	}
+}
+
+func TestParseTitleQueryBlank(t *testing.T) {
+	q, err := ParseTitleQuery("   ")
+	if err != nil || q != nil {
+		t.Fatalf("Expected a nil query for blank input, got %v, %v", q, err)
+	}
+	if !q.MatchesPage("<html><body>anything</body></html>") {
+		t.Error("Expected a nil query to match every page")
+	}
+}
+
+func TestTitleQueryTermsAndText(t *testing.T) {
+	q, err := ParseTitleQuery(`"line cook" OR chef -sous`)
+	if err != nil {
+		t.Fatalf("ParseTitleQuery: %v", err)
+	}
+	if terms := q.Terms(); len(terms) != 2 || terms[0] != "line cook" || terms[1] != "chef" {
+		t.Errorf("Expected terms [line cook chef], got %v", terms)
+	}
+	if !q.MatchesText("Line Cooks (nights)") || q.MatchesText("Sous Chef") {
+		t.Error("Expected posting titles to be matched without context")
+	}
+}
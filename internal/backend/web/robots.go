@@ -0,0 +1,342 @@
+// robots.txt support: fetches and parses the file once per host and answers whether a url may be crawled.
+package web
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// returned (wrapped) when robots.txt does not let us fetch a page
+var ErrRobotsDisallowed = errors.New("disallowed by robots.txt")
+
+const (
+	// how long a host's robots.txt is trusted before it is fetched again
+	robotsTTL = 24 * time.Hour
+	// robots.txt files past this size are cut off, same limit google uses
+	maxRobotsSize = 500 * 1024
+	// crawl-delays above this are clamped, a site asking for minutes between requests would stall the whole job
+	maxCrawlDelay = 10 * time.Second
+)
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// rules that apply to one user agent
+type RobotsRules struct {
+	rules      []robotsRule
+	CrawlDelay time.Duration
+	Sitemaps   []string
+}
+
+var (
+	allowAll    = &RobotsRules{}
+	disallowAll = &RobotsRules{rules: []robotsRule{{allow: false, pattern: "/"}}}
+)
+
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// parse a robots.txt and keep the group that best matches userAgent, falling back to the * group
+func ParseRobots(r io.Reader, userAgent string) *RobotsRules {
+	var (
+		groups   []*robotsGroup
+		current  *robotsGroup
+		sitemaps []string
+		// consecutive user-agent lines share one group
+		inAgents bool
+	)
+
+	scanner := bufio.NewScanner(io.LimitReader(r, maxRobotsSize))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				current = &robotsGroup{}
+				groups = append(groups, current)
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			inAgents = true
+			continue
+		case "allow", "disallow":
+			// an empty disallow means everything is allowed, nothing to record
+			if current != nil && value != "" {
+				current.rules = append(current.rules, robotsRule{allow: key == "allow", pattern: value})
+			}
+		case "crawl-delay":
+			if current != nil {
+				if secs, err := strconv.ParseFloat(value, 64); err == nil && secs > 0 {
+					current.crawlDelay = time.Duration(secs * float64(time.Second))
+				}
+			}
+		case "sitemap":
+			// sitemaps are global, not part of any group
+			sitemaps = append(sitemaps, value)
+		}
+		inAgents = false
+	}
+
+	rules := &RobotsRules{Sitemaps: sitemaps}
+	if g := matchGroup(groups, userAgent); g != nil {
+		rules.rules = g.rules
+		rules.CrawlDelay = g.crawlDelay
+	}
+	return rules
+}
+
+// the group naming the longest part of our user agent wins, * only applies when nothing names us
+func matchGroup(groups []*robotsGroup, userAgent string) *robotsGroup {
+	ua := strings.ToLower(userAgent)
+	// only the product token counts, "JobBot/1.0 (+https://...)" is matched as "jobbot"
+	if i := strings.IndexAny(ua, "/ "); i >= 0 {
+		ua = ua[:i]
+	}
+
+	var best, wildcard *robotsGroup
+	bestLen := 0
+	for _, g := range groups {
+		for _, agent := range g.agents {
+			if agent == "*" {
+				if wildcard == nil {
+					wildcard = g
+				}
+				continue
+			}
+			if ua != "" && strings.Contains(ua, agent) && len(agent) > bestLen {
+				best, bestLen = g, len(agent)
+			}
+		}
+	}
+	if best != nil {
+		return best
+	}
+	return wildcard
+}
+
+// longest matching rule decides, allow wins a tie. no matching rule means allowed
+func (r *RobotsRules) Allowed(path string) bool {
+	if path == "" {
+		path = "/"
+	}
+	// robots.txt itself is always fetchable
+	if path == "/robots.txt" {
+		return true
+	}
+
+	allowed, matchLen := true, -1
+	for _, rule := range r.rules {
+		if !matchPattern(rule.pattern, path) {
+			continue
+		}
+		n := len(rule.pattern)
+		if n > matchLen || (n == matchLen && rule.allow) {
+			allowed, matchLen = rule.allow, n
+		}
+	}
+	return allowed
+}
+
+// robots patterns are prefixes with * wildcards and an optional $ end anchor
+func matchPattern(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = strings.TrimSuffix(pattern, "$")
+	}
+
+	parts := strings.Split(pattern, "*")
+	// first part has to be a prefix
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+	for _, part := range parts[1:] {
+		i := strings.Index(path[pos:], part)
+		if i < 0 {
+			return false
+		}
+		pos += i + len(part)
+	}
+	if !anchored {
+		return true
+	}
+	// the last piece must line up with the end of the path
+	last := parts[len(parts)-1]
+	return pos == len(path) || (len(parts) > 1 && strings.HasSuffix(path, last))
+}
+
+type robotsEntry struct {
+	rules   *RobotsRules
+	fetched time.Time
+	// earliest time the next request to this host may go out, for crawl-delay
+	next time.Time
+}
+
+// per-host robots.txt cache, safe for concurrent use by the worker pool
+type RobotsCache struct {
+	Client    *http.Client
+	UserAgent string
+	TTL       time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*robotsEntry
+	// one fetch per host at a time, workers hitting the same site wait for it
+	inflight map[string]chan struct{}
+}
+
+func NewRobotsCache(client *http.Client, userAgent string) *RobotsCache {
+	return &RobotsCache{
+		Client:    client,
+		UserAgent: userAgent,
+		TTL:       robotsTTL,
+		hosts:     make(map[string]*robotsEntry),
+		inflight:  make(map[string]chan struct{}),
+	}
+}
+
+// whether pageURL may be crawled, fetching robots.txt for its host when needed
+func (c *RobotsCache) Allowed(ctx context.Context, pageURL string) (bool, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return false, err
+	}
+	rules, err := c.Rules(ctx, u)
+	if err != nil {
+		return false, err
+	}
+	return rules.Allowed(u.EscapedPath()), nil
+}
+
+// block until the host's crawl-delay since our last request has passed
+func (c *RobotsCache) Wait(ctx context.Context, pageURL string) error {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return err
+	}
+	rules, err := c.Rules(ctx, u)
+	if err != nil {
+		return err
+	}
+	delay := rules.CrawlDelay
+	if delay <= 0 {
+		return nil
+	}
+	if delay > maxCrawlDelay {
+		delay = maxCrawlDelay
+	}
+
+	// reserve a slot so concurrent workers on the same host queue up behind each other
+	c.mu.Lock()
+	entry := c.hosts[robotsKey(u)]
+	now := time.Now()
+	at := now
+	if entry != nil {
+		if entry.next.After(at) {
+			at = entry.next
+		}
+		entry.next = at.Add(delay)
+	}
+	c.mu.Unlock()
+
+	return sleepCtx(ctx, at.Sub(now))
+}
+
+// rules for the host of u, cached for TTL
+func (c *RobotsCache) Rules(ctx context.Context, u *url.URL) (*RobotsRules, error) {
+	key := robotsKey(u)
+	for {
+		c.mu.Lock()
+		if entry, ok := c.hosts[key]; ok && time.Since(entry.fetched) < c.TTL {
+			c.mu.Unlock()
+			return entry.rules, nil
+		}
+		wait, busy := c.inflight[key]
+		if !busy {
+			done := make(chan struct{})
+			c.inflight[key] = done
+			c.mu.Unlock()
+
+			rules, err := c.fetch(ctx, u)
+
+			c.mu.Lock()
+			delete(c.inflight, key)
+			if err == nil {
+				c.hosts[key] = &robotsEntry{rules: rules, fetched: time.Now()}
+			}
+			c.mu.Unlock()
+			close(done)
+			return rules, err
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// fetch and parse robots.txt. a missing file allows everything, a server error blocks the host until the next fetch (RFC 9309)
+func (c *RobotsCache) fetch(ctx context.Context, u *url.URL) (*RobotsRules, error) {
+	robotsURL := (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}).String()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		// unreachable host, the page fetch will report the real error
+		return allowAll, nil
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return disallowAll, nil
+	case resp.StatusCode >= 400:
+		return allowAll, nil
+	case resp.StatusCode >= 300:
+		// redirects are followed by the client, anything left over is unusable
+		return allowAll, nil
+	}
+	return ParseRobots(resp.Body, c.UserAgent), nil
+}
+
+func robotsKey(u *url.URL) string {
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
+
+func robotsError(pageURL string) error {
+	return fmt.Errorf("%s: %w", pageURL, ErrRobotsDisallowed)
+}
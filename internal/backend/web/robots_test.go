@@ -0,0 +1,159 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testRobots = `
+# comments are ignored
+User-agent: *
+Disallow: /private
+Allow: /private/careers
+Disallow: /*.pdf$
+
+User-agent: cliscraper
+User-agent: otherbot
+Disallow: /careers
+Crawl-delay: 2
+
+Sitemap: https://example.com/sitemap.xml
+`
+
+func TestParseRobots(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		path      string
+		expected  bool
+	}{
+		{name: "Wildcard group allows unlisted path", userAgent: "somebot", path: "/about", expected: true},
+		{name: "Wildcard group disallows prefix", userAgent: "somebot", path: "/private/team", expected: false},
+		{name: "Longer allow wins", userAgent: "somebot", path: "/private/careers/open", expected: true},
+		{name: "Wildcard with end anchor", userAgent: "somebot", path: "/files/menu.pdf", expected: false},
+		{name: "End anchor does not match longer path", userAgent: "somebot", path: "/files/menu.pdf.html", expected: true},
+		{name: "Named group replaces wildcard group", userAgent: "cliscraper/1.0", path: "/private/team", expected: true},
+		{name: "Named group disallow", userAgent: "cliscraper/1.0", path: "/careers", expected: false},
+		{name: "Grouped user agents share rules", userAgent: "OtherBot", path: "/careers/jobs", expected: false},
+		{name: "robots.txt is always allowed", userAgent: "cliscraper", path: "/robots.txt", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := ParseRobots(strings.NewReader(testRobots), tt.userAgent)
+			if got := rules.Allowed(tt.path); got != tt.expected {
+				t.Errorf("Allowed(%q) for %s = %v, expected %v", tt.path, tt.userAgent, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseRobotsCrawlDelayAndSitemaps(t *testing.T) {
+	rules := ParseRobots(strings.NewReader(testRobots), "cliscraper/1.0")
+	if rules.CrawlDelay != 2*time.Second {
+		t.Errorf("Expected crawl delay 2s, got %v", rules.CrawlDelay)
+	}
+	if len(rules.Sitemaps) != 1 || rules.Sitemaps[0] != "https://example.com/sitemap.xml" {
+		t.Errorf("Expected sitemap to be collected, got %v", rules.Sitemaps)
+	}
+
+	if rules := ParseRobots(strings.NewReader(testRobots), "somebot"); rules.CrawlDelay != 0 {
+		t.Errorf("Expected no crawl delay for wildcard group, got %v", rules.CrawlDelay)
+	}
+}
+
+func TestRobotsCacheFetchesOncePerHost(t *testing.T) {
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			fetches.Add(1)
+			if ua := r.Header.Get("User-Agent"); ua != "testbot" {
+				t.Errorf("Expected user agent testbot, got %q", ua)
+			}
+			fmt.Fprint(w, "User-agent: *\nDisallow: /admin\n")
+		}
+	}))
+	defer srv.Close()
+
+	cache := NewRobotsCache(srv.Client(), "testbot")
+	for _, path := range []string{"/", "/admin", "/jobs"} {
+		allowed, err := cache.Allowed(context.Background(), srv.URL+path)
+		if err != nil {
+			t.Fatalf("Allowed(%s): %v", path, err)
+		}
+		if allowed == (path == "/admin") {
+			t.Errorf("Unexpected decision %v for %s", allowed, path)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("Expected robots.txt to be fetched once, got %d", n)
+	}
+}
+
+func TestRobotsCacheStatusCodes(t *testing.T) {
+	tests := []struct {
+		status   int
+		expected bool
+	}{
+		{status: http.StatusNotFound, expected: true},
+		{status: http.StatusForbidden, expected: true},
+		{status: http.StatusServiceUnavailable, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			allowed, err := NewRobotsCache(srv.Client(), "testbot").Allowed(context.Background(), srv.URL+"/careers")
+			if err != nil {
+				t.Fatalf("Allowed: %v", err)
+			}
+			if allowed != tt.expected {
+				t.Errorf("Expected %v for status %d, got %v", tt.expected, tt.status, allowed)
+			}
+		})
+	}
+}
+
+func TestScraperRespectsRobots(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			fmt.Fprint(w, "User-agent: *\nDisallow: /careers\n")
+		case "/":
+			fmt.Fprint(w, `<html><body><a href="/careers">Careers</a></body></html>`)
+		case "/careers":
+			t.Error("Disallowed careers page was fetched")
+		}
+	}))
+	defer srv.Close()
+
+	s := NewScraper("testbot")
+	s.Client = srv.Client()
+	s.Robots = NewRobotsCache(srv.Client(), "testbot")
+
+	found, err := s.Scrape(context.Background(), srv.URL+"/", []string{"cook"})
+	if found.JobPage != "" {
+		t.Errorf("Expected no job page, got %s", found.JobPage)
+	}
+	if !errors.Is(err, ErrRobotsDisallowed) {
+		t.Errorf("Expected ErrRobotsDisallowed, got %v", err)
+	}
+
+	pool := NewWorkerPool(1, 5*time.Second)
+	pool.Scraper = s
+	results := pool.Run(context.Background(), []Job{{BusinessName: "Blocked Company", URL: srv.URL + "/careers"}})
+	if len(results) != 1 || results[0].Status != StatusBlocked {
+		t.Errorf("Expected a %s result, got %+v", StatusBlocked, results)
+	}
+}
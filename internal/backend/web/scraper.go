@@ -2,19 +2,23 @@
 package web
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"io"
+	"log"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
 	"time"
 
-	"golang.org/x/net/html"
+	"cliscraper/internal/backend/ats"
+	"cliscraper/internal/database"
 )
 
 /* take a root website URL and tries to find a careers/job page.
 if the root is genuinely a careers page, it will still be returned.
-if the root just mentions jobs but has a dedicated /careers or /jobs link (etc), the scraper follows links and returns the designated jobs page.
+if the root just mentions jobs but has a dedicated /careers or /jobs link (etc), the scraper crawls the site (see crawler.go) and returns the designated jobs page.
 only if nothing better is found AND there seems to be careers does it fall back to root.
 */
 
@@ -22,97 +26,275 @@ var httpClient = &http.Client{
 	Timeout: 10 * time.Second,
 }
 
-func ScrapeWebsite(rootURL string, titles []string) (string, error) {
-	// fetch url root and checks if responds 
-	resp, err := httpClient.Get(rootURL)
-	if err != nil {
-		return "", fmt.Errorf("failed to fetch %s: %w", rootURL, err)
+// sent with every request unless SCRAPER_USER_AGENT says otherwise, robots.txt groups are matched against it
+const DefaultUserAgent = "cliscraper/1.0"
+
+// holds what has to be shared between workers: the http client, our user agent and the robots.txt cache
+type Scraper struct {
+	Client    *http.Client
+	UserAgent string
+	// crawl budget per site, 0 uses the defaults
+	MaxDepth int
+	MaxPages int
+	// nil skips robots.txt checks entirely
+	Robots *RobotsCache
+	// board api adapters used once an ATS is detected, nil skips the api calls
+	ATS ats.Adapters
+	// per-host and global request limits, nil means unlimited
+	Limiter *Limiter
+	// lowest ClassifyPage score accepted as a job page, 0 accepts any page that matches
+	MinScore float64
+	// keywords and heuristics, nil uses DefaultProfile
+	Profile *Profile
+	// bodies over this many bytes are not read, 0 uses the default
+	MaxBodyBytes int64
+	// careers pages remembered per domain, nil always runs the full discovery
+	Domains DomainStore
+	// on-disk copies of fetched pages, nil fetches everything
+	Cache *HTTPCache
+
+	// counts cache use for one pool run, see WorkerPool.CacheStats
+	cacheStats *CacheStats
+}
+
+func NewScraper(userAgent string) *Scraper {
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	return &Scraper{
+		Client:    httpClient,
+		UserAgent: userAgent,
+		Robots:    NewRobotsCache(httpClient, userAgent),
+		ATS:       ats.NewAdaptersFromEnv(),
 	}
-	defer resp.Body.Close()
+}
 
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read body: %w", err)
+// scraper configured from the SCRAPER_USER_AGENT env var
+func NewScraperFromEnv() *Scraper {
+	return NewScraper(os.Getenv("SCRAPER_USER_AGENT"))
+}
+
+// used by ScrapeWebsite and worker pools without their own scraper
+var defaultScraper = NewScraper(DefaultUserAgent)
+
+func ScrapeWebsite(ctx context.Context, rootURL string, titles []string) (string, error) {
+	found, err := defaultScraper.Scrape(ctx, rootURL, titles)
+	return found.JobPage, err
+}
+
+// what a scrape turned up for one site
+type Finding struct {
+	// empty when no page matched
+	JobPage string
+	// ATS boards linked or embedded on the pages we looked at, the one on the job page comes first
+	ATS []ATSMatch
+	// openings matching the title query: JobPosting markup on the job page, then the board api of the first ATS with an adapter
+	Jobs []database.Job
+	// how confident we are JobPage is a careers page, with the signals that fired
+	Score   float64
+	Reasons []string
+	// the negative phrase when JobPage is a careers page saying there are no openings, e.g. "not currently hiring"
+	NotHiring string
+	// pdfs that look like job descriptions or printable applications, listed since we cannot read them
+	Documents []string
+	// where the root url redirected to, and whether the site is parked, social-only or dead
+	Site SiteInfo
+	// careers paths probed after the crawl found nothing, and whether JobPage came from one of them
+	Probes   int
+	ProbeHit bool
+	// JobPage is the careers page or board remembered from an earlier scrape, see domains.go
+	Remembered bool
+}
+
+// a root blocked by robots.txt comes back as ErrRobotsDisallowed, so does an empty result when
+// careers links had to be skipped for robots.txt, since the answer may sit behind them
+func (s *Scraper) Scrape(ctx context.Context, rootURL string, titles []string) (Finding, error) {
+	return s.ScrapeQuery(ctx, rootURL, TitlesQuery(titles))
+}
+
+// Scrape for a parsed title query, nil matches any job page. with a domain store the remembered careers page is tried first
+func (s *Scraper) ScrapeQuery(ctx context.Context, rootURL string, q *TitleQuery) (Finding, error) {
+	known := s.recall(ctx, rootURL)
+	if found, ok := s.revisit(ctx, known, q); ok {
+		s.remember(ctx, rootURL, known, found, nil)
+		return found, nil
 	}
-	body := string(bodyBytes)
+	found, err := s.discover(ctx, rootURL, q)
+	s.remember(ctx, rootURL, known, found, err)
+	return found, err
+}
 
+// crawl the site from its root, then ask the board api of any ATS seen
+func (s *Scraper) discover(ctx context.Context, rootURL string, q *TitleQuery) (Finding, error) {
+	found, err := s.scrapePages(ctx, rootURL, q)
+	if len(found.ATS) == 0 || ctx.Err() != nil {
+		return found, err
+	}
 
-	if IsJobPage(rootURL, body) {
-	// instead of returning immediately, record it as a candidate
-		candidate := rootURL
+	s.boardPostings(ctx, &found, q)
+	// openings from the board api answer the question even when robots.txt kept us off the careers page
+	if found.JobPage != "" && errors.Is(err, ErrRobotsDisallowed) {
+		err = nil
+	}
+	return found, err
+}
 
-		if MatchesJobTitle(body, titles) {
-			return candidate, nil
+// ask the board api for real openings. an embedded board is usually rendered by javascript,
+// so the api is the only way to see what is posted. failures only lose the postings, not the site
+func (s *Scraper) boardPostings(ctx context.Context, found *Finding, q *TitleQuery) {
+	if s.ATS == nil {
+		return
+	}
+	for _, m := range found.ATS {
+		adapter, ok := s.ATS[m.Vendor]
+		if !ok || m.Board == "" {
+			continue
 		}
-    //return "", nil // fallback: uncomment to always return root if it’s a job page
-	}
-
-	// parse HTML and scan links
-	pageLinks := extractLinks(body, rootURL)
-	for _, link := range pageLinks {
-		// quick keyword check before fetching
-		for _, kw := range JobPageKeywords {
-			if strings.Contains(strings.ToLower(link), kw) {
-				// fetch link and confirm it’s a job page
-				jobURL, ok := checkLink(link, titles)
-				if ok {
-					return jobURL, nil
-				}
+
+		if s.Limiter != nil {
+			release, err := s.Limiter.Wait(ctx, adapter.APIURL(m.Board))
+			if err != nil {
+				return
 			}
+			defer release()
+		}
+		jobs, err := adapter.Postings(ctx, m.Board)
+		if err != nil {
+			log.Printf("Fetching %s postings for %s failed: %v", m.Vendor, m.Board, err)
+			return
 		}
-	}
 
-	return "", nil // nothing found
+		jobs = filterJobs(jobs, q)
+		found.Jobs = mergeJobs(found.Jobs, jobs)
+		// the board is more up to date than a careers page saying there is nothing open
+		if (found.JobPage == "" || found.NotHiring != "") && len(jobs) > 0 {
+			found.JobPage = adapter.BoardURL(m.Board)
+			found.NotHiring = ""
+			// openings straight from the vendor leave no doubt about the page
+			found.Score = 1
+			found.Reasons = []string{fmt.Sprintf("%s: %d %s postings", SignalBoardAPI, len(jobs), ATSName(m.Vendor))}
+		}
+		return
+	}
 }
 
-// fetch a link and applies IsJobPage
-func checkLink(link string, titles []string) (string, bool) {
-	resp, err := httpClient.Get(link)
+// fetch the root and crawl the site from there until a page matches
+func (s *Scraper) scrapePages(ctx context.Context, rootURL string, q *TitleQuery) (Finding, error) {
+	// fetch url root and checks if responds 
+	page, err := s.fetchPage(ctx, rootURL)
+	var site SiteInfo
+	if len(page.Redirects) > 0 {
+		site.FinalURL, site.Redirects = page.URL, page.Redirects
+	}
 	if err != nil {
-		return "", false
+		if errors.Is(err, ErrRobotsDisallowed) {
+			return Finding{Site: site}, err
+		}
+		if deadFailure(err) {
+			site.Flag = SiteDead
+		}
+		return Finding{Site: site}, fmt.Errorf("failed to fetch %s: %w", rootURL, err)
 	}
-	defer resp.Body.Close()
+	// the final url, so a site that moved to a new domain is crawled there
+	doc := ParseDocument(page.URL, page.Body)
+	// nothing to crawl on a parking page or a social media profile
+	if site.Flag = siteFlag(doc); site.Flag != "" {
+		return Finding{Site: site}, nil
+	}
+	rootATS := detectPageATS(doc)
 
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", false
+	found, err := s.crawl(ctx, doc, q)
+	found.ATS = mergeATS(found.ATS, rootATS)
+	found.Site = site
+	return found, err
+}
+
+func (s *Scraper) profile() *Profile {
+	if s.Profile == nil {
+		return DefaultProfile()
+	}
+	return s.Profile
+}
+
+// a page is a hit when it reads like a job page satisfying the query, or when its JobPosting markup lists a matching opening
+// (that markup lives in <script> tags the visible text check never sees), and the classifier scores it at least MinScore.
+// skipped urls never match. a careers page with a negative phrase ("no open positions") and no openings in its markup
+// matches whatever the query, with NotHiring set
+func (s *Scraper) matchPage(doc *Document, q *TitleQuery) (Finding, bool) {
+	p := s.profile()
+	if p.SkipURL(doc.URL) {
+		return Finding{}, false
+	}
+
+	var jobs []database.Job
+	for _, posting := range doc.JobPostings() {
+		jobs = append(jobs, posting.Job())
+	}
+	jobs = filterJobs(jobs, q)
+
+	var notHiring string
+	if len(jobs) == 0 {
+		notHiring = p.negativePhrase(doc)
+		if !looksLikeJobPage(p, doc) || (notHiring == "" && !p.matchesQuery(doc, q)) {
+			return Finding{}, false
+		}
+	}
+	c := p.classify(doc, q)
+	if c.Score < s.MinScore {
+		return Finding{}, false
+	}
+	reasons := c.Reasons()
+	if notHiring != "" {
+		reasons = append(reasons, fmt.Sprintf("%s: %q", SignalNegativePhrase, notHiring))
 	}
-	body := string(bodyBytes)
-	// debug print
-	//fmt.Printf("Checking candidate link: %s\n", link)
+	return Finding{JobPage: doc.URL, ATS: detectPageATS(doc), Jobs: jobs, Score: c.Score, Reasons: reasons, NotHiring: notHiring}, true
+}
 
-	if IsJobPage(link, body) && MatchesJobTitle(body, titles) {
-		return link, true
+// an ATS board is a job page whatever its wording
+func looksLikeJobPage(p *Profile, doc *Document) bool {
+	if _, ok := MatchATS(doc.URL); ok {
+		return true
 	}
-	return "", false
+	return p.isJobPage(doc)
 }
 
-// this is only parsing <a href=".."> links from the HTML body
-func extractLinks(body, base string) []string {
-	var links []string
-	doc, err := html.Parse(strings.NewReader(body))
-	if err != nil {
-		return links
-	}
-
-	var f func(*html.Node)
-	f = func(n *html.Node) {
-		if n.Type == html.ElementNode && n.Data == "a" {
-			for _, attr := range n.Attr {
-				if attr.Key == "href" {
-					href := resolveURL(base, attr.Val)
-					if href != "" {
-						links = append(links, href)
-					}
-				}
-			}
+// the page's own url counts when we landed on an ATS board directly
+func detectPageATS(doc *Document) []ATSMatch {
+	var found []ATSMatch
+	if m, ok := MatchATS(doc.URL); ok {
+		found = append(found, m)
+	}
+	return mergeATS(found, doc.ATS())
+}
+
+// append postings not already listed, the same opening often shows up in markup and in the board api
+func mergeJobs(existing, more []database.Job) []database.Job {
+	seen := make(map[string]bool, len(existing))
+	for _, j := range existing {
+		seen[j.URL+"|"+strings.ToLower(j.Title)] = true
+	}
+	for _, j := range more {
+		key := j.URL + "|" + strings.ToLower(j.Title)
+		if !seen[key] {
+			seen[key] = true
+			existing = append(existing, j)
 		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			f(c)
+	}
+	return existing
+}
+
+// concatenate keeping the first of each vendor/board
+func mergeATS(first, second []ATSMatch) []ATSMatch {
+	out := make([]ATSMatch, 0, len(first)+len(second))
+	seen := make(map[string]bool)
+	for _, m := range append(first, second...) {
+		key := m.Vendor + "|" + strings.ToLower(m.Board)
+		if !seen[key] {
+			seen[key] = true
+			out = append(out, m)
 		}
 	}
-	f(doc)
-	return links
+	return out
 }
 
 // resolve relative/absolute URLs
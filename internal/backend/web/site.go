@@ -0,0 +1,87 @@
+// the state of a business website as a whole: where its url ended up after redirects, and whether it is parked,
+// only a social media profile or dead. OSM website tags go stale, this tells a broken web presence apart from "no careers page".
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// why a website is no use for finding jobs, empty for a working site
+type SiteFlag string
+
+const (
+	SiteParked     SiteFlag = "parked"      // domain parking or for-sale page
+	SiteSocialOnly SiteFlag = "social_only" // the website is a facebook, instagram... profile
+	SiteDead       SiteFlag = "dead"        // does not resolve, refuses connections or answers 404/410
+)
+
+// where the root url led
+type SiteInfo struct {
+	// final url after redirects and every url that redirected on the way, the requested one first. both empty without redirects
+	FinalURL  string
+	Redirects []string
+	Flag      SiteFlag
+}
+
+// hosts that only ever serve parking or domain sale pages
+var parkingHosts = []string{
+	"sedoparking.com", "sedo.com", "parkingcrew.net", "bodis.com", "dan.com", "afternic.com", "hugedomains.com",
+	"above.com", "parked-content.godaddy.com", "domainmarket.com", "undeveloped.com", "parklogic.com",
+}
+
+// wording of parking and for-sale pages
+var parkedPhrases = []string{
+	"this domain is for sale", "this domain may be for sale", "domain name is for sale", "buy this domain",
+	"make an offer on this domain", "this domain is parked", "parked free", "domain parking", "this domain has expired",
+	"the domain has expired", "is available for purchase",
+}
+
+var socialHosts = []string{
+	"facebook.com", "fb.com", "fb.me", "instagram.com", "twitter.com", "x.com", "linkedin.com", "tiktok.com",
+	"yelp.com", "linktr.ee", "youtube.com", "pinterest.com",
+}
+
+// parked or social-only judging by the page the root url ended up on, empty for anything else
+func siteFlag(doc *Document) SiteFlag {
+	u, err := url.Parse(doc.URL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case hostIn(host, socialHosts):
+		return SiteSocialOnly
+	case hostIn(host, parkingHosts),
+		containsAny(strings.ToLower(doc.Title), parkedPhrases),
+		containsAny(strings.Join(strings.Fields(doc.Text), " "), parkedPhrases):
+		return SiteParked
+	}
+	return ""
+}
+
+// failures that mean nobody is running the site: the name does not resolve, nothing listens or the homepage is gone.
+// a timeout, a robots block, a bot wall (401/403), a rate limit (429) or a passing 5xx come from a live site
+func deadFailure(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Code == http.StatusNotFound || statusErr.Code == http.StatusGone
+	}
+	switch ClassifyFailure(err) {
+	case FailureDNS, FailureRefused:
+		return true
+	}
+	return false
+}
+
+// host is one of hosts or a subdomain of one
+func hostIn(host string, hosts []string) bool {
+	for _, h := range hosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
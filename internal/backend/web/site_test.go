@@ -0,0 +1,103 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSiteFlag(t *testing.T) {
+	tests := []struct {
+		name     string
+		pageURL  string
+		body     string
+		expected SiteFlag
+	}{
+		{name: "Business site", pageURL: "https://riverside.example/", body: "<title>Riverside Bistro</title><p>Open daily, book a table</p>"},
+		{name: "Parking host", pageURL: "https://www.sedoparking.com/riverside.example", body: "<p>Related links</p>", expected: SiteParked},
+		{name: "For sale page", pageURL: "https://riverside.example/", body: "<h1>This domain is   for sale!</h1><p>Make an offer</p>", expected: SiteParked},
+		{name: "Parked title", pageURL: "https://riverside.example/", body: "<title>Buy this domain</title>", expected: SiteParked},
+		{name: "Facebook page", pageURL: "https://m.facebook.com/riversidebistro", body: "<p>Riverside Bistro</p>", expected: SiteSocialOnly},
+		{name: "Link in bio", pageURL: "https://linktr.ee/riverside", body: "<p>Menu</p>", expected: SiteSocialOnly},
+		{name: "Lookalike host", pageURL: "https://notfacebook.com/", body: "<p>Menu</p>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if flag := siteFlag(ParseDocument(tt.pageURL, tt.body)); flag != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, flag)
+			}
+		})
+	}
+}
+
+func TestScrapeFollowsRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/", http.RedirectHandler("/home", http.StatusMovedPermanently))
+	mux.Handle("/home", http.RedirectHandler("/new/", http.StatusFound))
+	mux.HandleFunc("/new/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><a href="/new/jobs">Careers</a></body></html>`))
+	})
+	mux.HandleFunc("/new/jobs", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(cookOpening))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	found, err := newTestScraper(srv).Scrape(context.Background(), srv.URL+"/", []string{"cook"})
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if found.JobPage != srv.URL+"/new/jobs" {
+		t.Errorf("Expected the job page under the final url, got %q", found.JobPage)
+	}
+	if found.Site.FinalURL != srv.URL+"/new/" {
+		t.Errorf("Expected final url %s/new/, got %q", srv.URL, found.Site.FinalURL)
+	}
+	expected := []string{srv.URL + "/", srv.URL + "/home"}
+	if strings.Join(found.Site.Redirects, " ") != strings.Join(expected, " ") || found.Site.Flag != "" {
+		t.Errorf("Expected redirects %v and no flag, got %+v", expected, found.Site)
+	}
+}
+
+func TestScrapeFlagsSites(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected SiteFlag
+	}{
+		{name: "Parked", body: `<h1>riverside.example</h1><p>This domain may be for sale. <a href="/jobs">Jobs</a></p>`, expected: SiteParked},
+		{name: "Not found", status: http.StatusNotFound, expected: SiteDead},
+		{name: "Gone", status: http.StatusGone, expected: SiteDead},
+		// alive behind a bot wall, a rate limit or a bad moment
+		{name: "Forbidden", status: http.StatusForbidden},
+		{name: "Rate limited", status: http.StatusTooManyRequests},
+		{name: "Server error", status: http.StatusBadGateway},
+		{name: "Working", body: `<p>Riverside Bistro</p>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			found, _ := newTestScraper(srv).Scrape(context.Background(), srv.URL+"/", []string{"cook"})
+			if found.Site.Flag != tt.expected {
+				t.Errorf("Expected flag %q, got %q", tt.expected, found.Site.Flag)
+			}
+			if tt.expected == SiteParked && requests != 1 {
+				t.Errorf("Expected a parked site not to be crawled, got %d requests", requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+// sitemap discovery: CMS sites list their careers page in sitemap.xml even when the menu is drawn by javascript and
+// the homepage has no link we can see. the sitemaps named in robots.txt (or /sitemap.xml) are read, indexes followed,
+// and the urls that look most like a careers page are handed to the crawl to confirm.
+package web
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	// sitemap files read per site, indexes included. big sites split into dozens, the careers page is in the first few
+	maxSitemaps = 4
+	// top ranked sitemap urls crawled
+	maxSitemapCandidates = 5
+	// uncompressed size cap, the sitemap protocol's own limit
+	maxSitemapBytes = 50 << 20
+)
+
+// a <urlset> or a <sitemapindex>, whichever the file is
+type sitemapFile struct {
+	URLs     []sitemapLoc `xml:"url"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+// the site's sitemaps as listed in robots.txt, /sitemap.xml when it lists none
+func (s *Scraper) sitemapURLs(ctx context.Context, root *url.URL) []string {
+	if s.Robots != nil {
+		if rules, err := s.Robots.Rules(ctx, root); err == nil && len(rules.Sitemaps) > 0 {
+			return rules.Sitemaps
+		}
+	}
+	return []string{root.Scheme + "://" + root.Host + "/sitemap.xml"}
+}
+
+// same-site urls from the sitemaps that look like a careers page, best first
+func (s *Scraper) sitemapCandidates(ctx context.Context, root *url.URL, rootHost string, visited map[string]bool) []crawlItem {
+	queue := s.sitemapURLs(ctx, root)
+	seen := make(map[string]bool)
+	var items []crawlItem
+	for read := 0; len(queue) > 0 && read < maxSitemaps && ctx.Err() == nil; {
+		sitemapURL := queue[0]
+		queue = queue[1:]
+		if seen[sitemapURL] {
+			continue
+		}
+		seen[sitemapURL] = true
+		read++
+
+		file, err := s.fetchSitemap(ctx, sitemapURL)
+		if err != nil {
+			continue
+		}
+		// child sitemaps with careers or page wording first, "page-sitemap.xml" rather than "product-sitemap3.xml"
+		children := make([]string, 0, len(file.Sitemaps))
+		for _, sm := range file.Sitemaps {
+			children = append(children, strings.TrimSpace(sm.Loc))
+		}
+		sort.SliceStable(children, func(i, j int) bool { return s.sitemapPriority(children[i]) > s.sitemapPriority(children[j]) })
+		queue = append(queue, children...)
+
+		for _, u := range file.URLs {
+			loc := strings.TrimSpace(u.Loc)
+			if !sameSite(rootHost, loc) {
+				continue
+			}
+			// only careers wording in the url counts, sitemaps have no anchor text
+			score := s.profile().scoreLink(anchor{URL: loc})
+			key := crawlKey(loc)
+			if score < scoreKeyword || visited[key] {
+				continue
+			}
+			visited[key] = true
+			items = append(items, crawlItem{url: loc, depth: 1, score: score})
+		}
+	}
+
+	// the careers page itself before the postings under it
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].score != items[j].score {
+			return items[i].score > items[j].score
+		}
+		return len(items[i].url) < len(items[j].url)
+	})
+	if len(items) > maxSitemapCandidates {
+		items = items[:maxSitemapCandidates]
+	}
+	return items
+}
+
+func (s *Scraper) sitemapPriority(sitemapURL string) int {
+	name := strings.ToLower(path.Base(sitemapURL))
+	switch {
+	case containsAny(name, s.profile().allKeywords()):
+		return 2
+	case strings.Contains(name, "page"):
+		return 1
+	}
+	return 0
+}
+
+// GET and parse one sitemap, gzipped or not. robots.txt and the limiter apply like for any page,
+// and with a cache the answer, a missing sitemap included, is kept for the TTL
+func (s *Scraper) fetchSitemap(ctx context.Context, sitemapURL string) (*sitemapFile, error) {
+	if err := s.allowed(ctx, sitemapURL); err != nil {
+		return nil, err
+	}
+	if e := s.Cache.result(sitemapKey(sitemapURL)); e != nil {
+		s.cacheStats.count(cacheHit)
+		if e.Status < 200 || e.Status > 299 {
+			return nil, &StatusError{URL: sitemapURL, Code: e.Status}
+		}
+		return readSitemap(sitemapURL, e.Body)
+	}
+	release, err := s.throttle(ctx, sitemapURL)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sitemapURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", s.UserAgent)
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if s.Cache != nil {
+			s.cacheStats.count(cacheMiss)
+			s.Cache.putResult(sitemapKey(sitemapURL), resp.StatusCode, sitemapURL, resp.Header, nil)
+		}
+		return nil, &StatusError{URL: sitemapURL, Code: resp.StatusCode}
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxSitemapBytes))
+	if err != nil {
+		return nil, err
+	}
+	if s.Cache != nil {
+		s.cacheStats.count(cacheMiss)
+		s.Cache.putResult(sitemapKey(sitemapURL), resp.StatusCode, sitemapURL, resp.Header, raw)
+	}
+	return readSitemap(sitemapURL, raw)
+}
+
+// parse a sitemap as served, unpacking it first when it is gzipped
+func readSitemap(sitemapURL string, raw []byte) (*sitemapFile, error) {
+	// sitemap.xml.gz, served as a file rather than with Content-Encoding, so the transport leaves it compressed
+	if bytes.HasPrefix(raw, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", sitemapURL, err)
+		}
+		if raw, err = io.ReadAll(io.LimitReader(zr, maxSitemapBytes)); err != nil {
+			return nil, fmt.Errorf("%s: %w", sitemapURL, err)
+		}
+	}
+	return parseSitemap(raw)
+}
+
+func parseSitemap(data []byte) (*sitemapFile, error) {
+	var file sitemapFile
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.CharsetReader = charset.NewReaderLabel
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid sitemap: %w", err)
+	}
+	return &file, nil
+}
@@ -0,0 +1,92 @@
+package web
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func urlset(locs ...string) string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+	for _, loc := range locs {
+		fmt.Fprintf(&sb, "<url><loc>%s</loc></url>", loc)
+	}
+	sb.WriteString("</urlset>")
+	return sb.String()
+}
+
+func TestScrapeFindsCareersPageInSitemap(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			// the menu is built by javascript, no links to follow
+			w.Write([]byte(`<html><body><div id="menu"></div><p>Riverside Bistro</p></body></html>`))
+		case "/robots.txt":
+			fmt.Fprintf(w, "User-agent: *\nDisallow: /admin\nSitemap: %s/sitemap_index.xml\n", srv.URL)
+		case "/sitemap_index.xml":
+			fmt.Fprintf(w, `<?xml version="1.0"?><sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+				<sitemap><loc>%[1]s/post-sitemap.xml</loc></sitemap><sitemap><loc>%[1]s/page-sitemap.xml.gz</loc></sitemap></sitemapindex>`, srv.URL)
+		case "/post-sitemap.xml":
+			w.Write([]byte(urlset(srv.URL+"/2019/new-menu", "https://partner.example/careers")))
+		case "/page-sitemap.xml.gz":
+			var buf bytes.Buffer
+			zw := gzip.NewWriter(&buf)
+			zw.Write([]byte(urlset(srv.URL+"/about", srv.URL+"/work-with-us/")))
+			zw.Close()
+			w.Header().Set("Content-Type", "application/x-gzip")
+			w.Write(buf.Bytes())
+		case "/work-with-us/":
+			w.Write([]byte(cookOpening))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	s := newTestScraper(srv)
+	s.Robots = NewRobotsCache(srv.Client(), "testbot")
+	found, err := s.Scrape(context.Background(), srv.URL+"/", []string{"cook"})
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if found.JobPage != srv.URL+"/work-with-us/" || found.ProbeHit {
+		t.Errorf("Expected the careers page from the gzipped sitemap, got %q (probe hit %v)", found.JobPage, found.ProbeHit)
+	}
+}
+
+func TestSitemapCandidates(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sitemap.xml" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(urlset(
+			srv.URL+"/careers/line-cook-2024", srv.URL+"/menu", srv.URL+"/careers/",
+			srv.URL+"/blog/now-hiring", "https://elsewhere.example/jobs", srv.URL+"/seen-jobs",
+		)))
+	}))
+	defer srv.Close()
+
+	root, _ := url.Parse(srv.URL + "/")
+	visited := map[string]bool{crawlKey(srv.URL + "/seen-jobs"): true}
+	items := newTestScraper(srv).sitemapCandidates(context.Background(), root, root.Host, visited)
+
+	// keyword urls only, shortest first, skipping blog urls, other sites and pages the crawl already has
+	expected := []string{srv.URL + "/careers/", srv.URL + "/careers/line-cook-2024"}
+	var got []string
+	for _, item := range items {
+		got = append(got, item.url)
+	}
+	if strings.Join(got, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
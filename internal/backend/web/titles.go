@@ -0,0 +1,218 @@
+// job title matching: titles and page text are tokenized and stemmed, so "Software Engineers" matches "software engineer",
+// and each search title is expanded through a synonym map so "nurse" also finds "RN".
+package web
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+
+	"cliscraper/internal/database"
+)
+
+// groups of interchangeable titles bundled into the binary, TITLE_SYNONYMS_FILE adds more on top
+//
+//go:embed data/synonyms.txt
+var synonymsTxt string
+
+// title phrase (stemmed tokens joined by spaces) -> every phrase in its groups
+type Synonyms map[string][]string
+
+// read one group per line, entries separated by commas, # starts a comment
+func LoadSynonyms(r io.Reader) (Synonyms, error) {
+	syn := make(Synonyms)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+
+		var group []string
+		for _, entry := range strings.Split(line, ",") {
+			if key := phraseKey(entry); key != "" {
+				group = append(group, key)
+			}
+		}
+		if len(group) < 2 {
+			continue
+		}
+		for _, key := range group {
+			syn[key] = appendUnique(syn[key], group...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading synonyms: %w", err)
+	}
+	return syn, nil
+}
+
+// the title and everything it is a synonym of, whole phrase first then single words swapped in place
+// ("software dev" -> "software developer", "software engineer"...)
+func (s Synonyms) Expand(title string) [][]string {
+	tokens := titleTokens(title)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	var out [][]string
+	add := func(phrase []string) {
+		key := strings.Join(phrase, " ")
+		if !seen[key] {
+			seen[key] = true
+			out = append(out, phrase)
+		}
+	}
+
+	add(tokens)
+	for _, alt := range s[strings.Join(tokens, " ")] {
+		add(strings.Fields(alt))
+	}
+	if len(tokens) > 1 {
+		for i, tok := range tokens {
+			for _, alt := range s[tok] {
+				phrase := append(append(append([]string{}, tokens[:i]...), strings.Fields(alt)...), tokens[i+1:]...)
+				add(phrase)
+			}
+		}
+	}
+	return out
+}
+
+var (
+	synonymsOnce sync.Once
+	synonyms     Synonyms
+)
+
+// the bundled groups plus TITLE_SYNONYMS_FILE when set, loaded once. a broken extra file is logged and skipped
+func defaultSynonyms() Synonyms {
+	synonymsOnce.Do(func() {
+		synonyms, _ = LoadSynonyms(strings.NewReader(synonymsTxt))
+		path := os.Getenv("TITLE_SYNONYMS_FILE")
+		if path == "" {
+			return
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			log.Printf("Loading title synonyms: %v", err)
+			return
+		}
+		defer f.Close()
+		extra, err := LoadSynonyms(f)
+		if err != nil {
+			log.Printf("Loading title synonyms from %s: %v", path, err)
+			return
+		}
+		for key, alts := range extra {
+			synonyms[key] = appendUnique(synonyms[key], alts...)
+		}
+	})
+	return synonyms
+}
+
+// lowercased, accent folded and stemmed word tokens. letters and digits of any script make up a word, and runs of
+// single letters are glued back together so "C.N.A." and "C N A" both read as "cna"
+func titleTokens(text string) []string {
+	raw := strings.FieldsFunc(foldAccents(strings.ToLower(text)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := make([]string, 0, len(raw))
+	for i := 0; i < len(raw); i++ {
+		if len(raw[i]) == 1 && i+1 < len(raw) && len(raw[i+1]) == 1 {
+			acronym := raw[i]
+			for i+1 < len(raw) && len(raw[i+1]) == 1 {
+				i++
+				acronym += raw[i]
+			}
+			tokens = append(tokens, acronym)
+			continue
+		}
+		tokens = append(tokens, stem(raw[i]))
+	}
+	return tokens
+}
+
+func phraseKey(phrase string) string {
+	return strings.Join(titleTokens(phrase), " ")
+}
+
+// light plural stemming, enough for "nurses", "positions", "baristas" or "companies" while leaving "business" and "status" alone.
+// a stem only has to agree between singular and plural, it need not be a word: "cache" and "caches" both give "cach",
+// "sale" and "sales" both give "sale". spanish "-iones" plurals ("posiciones") lose their "es" too
+func stem(w string) string {
+	switch {
+	case len(w) <= 3:
+		return w
+	case strings.HasSuffix(w, "iones"):
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "ies") && len(w) > 4:
+		return w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "sses"), strings.HasSuffix(w, "ches"), strings.HasSuffix(w, "shes"), strings.HasSuffix(w, "xes"):
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "che"), strings.HasSuffix(w, "she"):
+		// so "cache" meets "caches" the way "dish" meets "dishes"
+		return w[:len(w)-1]
+	case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") && !strings.HasSuffix(w, "us") && !strings.HasSuffix(w, "is"):
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+// index of every place the phrase appears in words
+func phraseIndexes(words, phrase []string) []int {
+	var found []int
+	if len(phrase) == 0 {
+		return found
+	}
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		match := true
+		for j, tok := range phrase {
+			if words[i+j] != tok {
+				match = false
+				break
+			}
+		}
+		if match {
+			found = append(found, i)
+		}
+	}
+	return found
+}
+
+// postings whose title satisfies the query, all of them for a nil query
+func filterJobs(jobs []database.Job, q *TitleQuery) []database.Job {
+	if q == nil {
+		return jobs
+	}
+	filtered := make([]database.Job, 0, len(jobs))
+	for _, j := range jobs {
+		if q.MatchesText(j.Title) {
+			filtered = append(filtered, j)
+		}
+	}
+	return filtered
+}
+
+func appendUnique(list []string, more ...string) []string {
+	for _, m := range more {
+		dup := false
+		for _, l := range list {
+			if l == m {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			list = append(list, m)
+		}
+	}
+	return list
+}
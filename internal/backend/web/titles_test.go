@@ -0,0 +1,118 @@
+package web
+
+import (
+	"strings"
+	"testing"
+
+	"cliscraper/internal/database"
+)
+
+func TestMatchesJobTitlePhrasesAndSynonyms(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		titles   []string
+		expected bool
+	}{
+		{
+			name:     "Multi-word phrase",
+			body:     "<html><body><h2>Software Engineer</h2><p>Apply now</p></body></html>",
+			titles:   []string{"software engineer"},
+			expected: true,
+		},
+		{
+			name:     "Phrase words apart do not match",
+			body:     "<html><body><p>Our software is great. Engineer your career, apply now</p></body></html>",
+			titles:   []string{"software engineer"},
+			expected: false,
+		},
+		{
+			name:     "Plural title",
+			body:     "<html><body><h2>Line Cooks wanted</h2><p>We are hiring</p></body></html>",
+			titles:   []string{"line cook"},
+			expected: true,
+		},
+		{
+			name:     "Acronym with punctuation",
+			body:     "<html><body><h2>C.N.A. - nights</h2><p>Full benefits, apply today</p></body></html>",
+			titles:   []string{"CNA"},
+			expected: true,
+		},
+		{
+			name:     "Synonym of the title",
+			body:     "<html><body><h2>RN - Med/Surg</h2><p>Open positions</p></body></html>",
+			titles:   []string{"nurse"},
+			expected: true,
+		},
+		{
+			name:     "Word swapped for a synonym",
+			body:     "<html><body><h2>Senior Software Developer</h2><p>Join us, apply below</p></body></html>",
+			titles:   []string{"software dev"},
+			expected: true,
+		},
+		{
+			name:     "Unrelated title",
+			body:     "<html><body><h2>Cashier</h2><p>Apply now</p></body></html>",
+			titles:   []string{"nurse"},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchesJobTitle(tt.body, tt.titles); got != tt.expected {
+				t.Errorf("MatchesJobTitle(%q, %v) = %v, want %v", tt.body, tt.titles, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadSynonyms(t *testing.T) {
+	syn, err := LoadSynonyms(strings.NewReader("# comment\nbarista, coffee maker\nsolo\n"))
+	if err != nil {
+		t.Fatalf("LoadSynonyms: %v", err)
+	}
+	if len(syn) != 2 {
+		t.Errorf("Expected 2 entries, got %v", syn)
+	}
+
+	var expanded []string
+	for _, phrase := range syn.Expand("Baristas") {
+		expanded = append(expanded, strings.Join(phrase, " "))
+	}
+	if len(expanded) != 2 || expanded[0] != "barista" || expanded[1] != "coffee maker" {
+		t.Errorf("Expected [barista coffee maker], got %v", expanded)
+	}
+}
+
+func TestStem(t *testing.T) {
+	tests := map[string]string{
+		"nurses":    "nurse",
+		"positions": "position",
+		"companies": "company",
+		"dishes":    "dish",
+		"cache":     "cach",
+		"caches":    "cach",
+		"sale":      "sale",
+		"sales":     "sale",
+		"business":  "business",
+		"status":    "status",
+		"rn":        "rn",
+	}
+	for in, expected := range tests {
+		if got := stem(in); got != expected {
+			t.Errorf("stem(%q) = %q, expected %q", in, got, expected)
+		}
+	}
+}
+
+func TestFilterJobs(t *testing.T) {
+	jobs := []database.Job{{Title: "Registered Nurse (RN)"}, {Title: "LPN - Nights"}, {Title: "Cashier"}}
+	filtered := filterJobs(jobs, TitlesQuery([]string{"nurse"}))
+	if len(filtered) != 2 {
+		t.Errorf("Expected both nursing postings, got %v", filtered)
+	}
+	if all := filterJobs(jobs, TitlesQuery([]string{" "})); len(all) != len(jobs) {
+		t.Errorf("Expected a blank title to keep every posting, got %v", all)
+	}
+}
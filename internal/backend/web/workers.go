@@ -1,9 +1,13 @@
 package web
 
 import (
+	"context"
+	"errors"
 	"log"
 	"sync"
 	"time"
+
+	"cliscraper/internal/database"
 )
 
 // a single scraping task.
@@ -11,18 +15,68 @@ type Job struct {
 	BusinessName string
 	URL    string
 	Titles []string
+	// parsed title query, takes over from Titles when set
+	Query  *TitleQuery
 }
 
+// how a job ended
+type ResultStatus string
+
+const (
+	StatusFound     ResultStatus = "found"
+	StatusNotHiring ResultStatus = "not_hiring" // careers page found, but it says there are no openings
+	StatusNotFound  ResultStatus = "not_found"
+	StatusFailed    ResultStatus = "failed"
+	StatusCancelled ResultStatus = "cancelled" // search was cancelled or ran out of time before/while this job ran
+	StatusBlocked   ResultStatus = "robots_blocked" // robots.txt kept us off the site or its careers links
+)
+
 type Result struct {
 	BusinessName string
 	URL          string
 	JobPage      string
+	// first ATS seen on the site, empty when it hosts its own listings
+	ATSVendor    string
+	ATSBoard     string
+	// openings read from the ATS board api
+	Jobs         []database.Job
+	// classifier confidence for JobPage and the signals behind it
+	Score        float64
+	Reasons      []string
+	// pdfs worth a look, see Finding.Documents
+	Documents    []string
+	Status       ResultStatus
 	Error        error
+	// why a failed or blocked site could not be scraped
+	Failure      FailureKind
+	// where URL redirected to and whether the site is parked, social-only or dead, see Finding.Site
+	Site         SiteInfo
+	// see Finding.Probes
+	Probes       int
+	ProbeHit     bool
+	// JobPage came from the domain store, see Finding.Remembered
+	Remembered   bool
 }
 
 type WorkerPool struct {
 	NumWorkers int
+	// deadline for a single site, 0 means no per-job limit
 	Timeout    time.Duration
+	// deadline for the whole run, 0 means only the caller's context applies
+	SearchTimeout time.Duration
+
+	// optional, called from the collecting goroutine as each result comes in
+	OnResult func(Result)
+	// shared by all workers, nil uses the package default
+	Scraper *Scraper
+	// politeness limits for this run, applied on top of the scraper's own Limiter. the zero value adds none
+	Limits Limits
+	// pages scoring below this are not accepted as job pages, 0 keeps the scraper's own threshold
+	MinScore float64
+	// detector profile for this run, nil keeps the scraper's own
+	Profile *Profile
+	// optional, counts the run's page cache hits, revalidations and misses as they happen
+	CacheStats *CacheStats
 }
 
 // defaults
@@ -34,8 +88,16 @@ func NewWorkerPool(numWorkers int, timeout time.Duration) *WorkerPool {
 }
 
 // lauch scraper concurrently using ScrapeWebsite() from scraper.go.
-func (wp *WorkerPool) Run(jobs []Job) []Result {
+// every job gets a result: once ctx is done (or SearchTimeout passes) in-flight fetches are aborted and jobs that never ran come back as cancelled
+func (wp *WorkerPool) Run(ctx context.Context, jobs []Job) []Result {
 	log.Printf("Starting worker pool with %d workers for %d jobs", wp.NumWorkers, len(jobs))
+	if wp.SearchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, wp.SearchTimeout)
+		defer cancel()
+	}
+	scraper := wp.scraper()
+
 	jobCh := make(chan Job, len(jobs))
 	resultCh := make(chan Result, len(jobs))
 
@@ -49,13 +111,12 @@ func (wp *WorkerPool) Run(jobs []Job) []Result {
 		go func(id int) {
 			defer wg.Done()
 			for job := range jobCh {
-				jobPage, err := ScrapeWebsite(job.URL, job.Titles)
-				resultCh <- Result{
-					BusinessName: job.BusinessName,
-					URL:          job.URL,
-					JobPage:      jobPage,
-					Error:        err,
+				// keep draining so every job still gets reported
+				if err := ctx.Err(); err != nil {
+					resultCh <- cancelledResult(job, err)
+					continue
 				}
+				resultCh <- wp.runJob(ctx, scraper, job)
 			}
 			log.Printf("Worker %d: Finished processing all jobs", id)
 		}(workerID)
@@ -75,9 +136,108 @@ func (wp *WorkerPool) Run(jobs []Job) []Result {
 
 	results := make([]Result, 0, len(jobs))
 	for res := range resultCh {
+		if wp.OnResult != nil {
+			wp.OnResult(res)
+		}
 		results = append(results, res)
 	}
 
 	log.Printf("Worker pool completed! Processed %d jobs, got %d results", len(jobs), len(results))
 	return results
 }
+
+// scraper for one run, limits, the score threshold, the profile and the cache stats are per run so each gets its own limiter on top of the shared robots cache
+func (wp *WorkerPool) scraper() *Scraper {
+	base := wp.Scraper
+	if base == nil {
+		base = defaultScraper
+	}
+	if wp.Limits == (Limits{}) && wp.MinScore == 0 && wp.Profile == nil && wp.CacheStats == nil {
+		return base
+	}
+	s := *base
+	if wp.Limits != (Limits{}) {
+		s.Limiter = s.Limiter.With(wp.Limits)
+	}
+	if wp.MinScore > 0 {
+		s.MinScore = wp.MinScore
+	}
+	if wp.Profile != nil {
+		s.Profile = wp.Profile
+	}
+	s.cacheStats = wp.CacheStats
+	return &s
+}
+
+// scrape one site under the per-job deadline
+func (wp *WorkerPool) runJob(ctx context.Context, scraper *Scraper, job Job) Result {
+	jobCtx := ctx
+	if wp.Timeout > 0 {
+		var cancel context.CancelFunc
+		jobCtx, cancel = context.WithTimeout(ctx, wp.Timeout)
+		defer cancel()
+	}
+
+	q := job.Query
+	if q == nil {
+		q = TitlesQuery(job.Titles)
+	}
+	found, err := scraper.ScrapeQuery(jobCtx, job.URL, q)
+	res := Result{
+		BusinessName: job.BusinessName,
+		URL:          job.URL,
+		JobPage:      found.JobPage,
+		Jobs:         found.Jobs,
+		Score:        found.Score,
+		Reasons:      found.Reasons,
+		Documents:    found.Documents,
+		Site:         found.Site,
+		Probes:       found.Probes,
+		ProbeHit:     found.ProbeHit,
+		Remembered:   found.Remembered,
+		Error:        err,
+	}
+	if len(found.ATS) > 0 {
+		res.ATSVendor = found.ATS[0].Vendor
+		res.ATSBoard = found.ATS[0].Board
+	}
+
+	if err != nil && ctx.Err() != nil {
+		// the whole search stopped, not this site's fault
+		res.Status = StatusCancelled
+		res.Error = ctx.Err()
+		return res
+	}
+	res.Status = findingStatus(found, err)
+	switch res.Status {
+	case StatusBlocked:
+		res.Failure = FailureRobots
+	case StatusFailed:
+		res.Failure = ClassifyFailure(err)
+	}
+	return res
+}
+
+// the status of a scrape that ran to the end
+func findingStatus(found Finding, err error) ResultStatus {
+	switch {
+	case errors.Is(err, ErrRobotsDisallowed):
+		return StatusBlocked
+	case err != nil:
+		return StatusFailed
+	case found.NotHiring != "":
+		return StatusNotHiring
+	case found.JobPage != "":
+		return StatusFound
+	}
+	return StatusNotFound
+}
+
+func cancelledResult(job Job, err error) Result {
+	return Result{
+		BusinessName: job.BusinessName,
+		URL:          job.URL,
+		Status:       StatusCancelled,
+		Error:        err,
+	}
+}
@@ -1,6 +1,10 @@
 package web
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 )
@@ -32,7 +36,7 @@ func TestWorkerPoolRun(t *testing.T) {
 	}
 	
 	// Run the worker pool
-	results := pool.Run(jobs)
+	results := pool.Run(context.Background(), jobs)
 	
 	// Verify we got results for all jobs
 	if len(results) != len(jobs) {
@@ -59,7 +63,7 @@ func TestWorkerPoolRun(t *testing.T) {
 func TestWorkerPoolWithEmptyJobs(t *testing.T) {
 	pool := NewWorkerPool(2, 5*time.Second)
 	
-	results := pool.Run([]Job{})
+	results := pool.Run(context.Background(), []Job{})
 	
 	if len(results) != 0 {
 		t.Errorf("Expected 0 results for empty job list, got %d", len(results))
@@ -73,7 +77,7 @@ func TestWorkerPoolWithSingleJob(t *testing.T) {
 		{BusinessName: "Single Company", URL: "https://httpbin.org/status/200", Titles: []string{"engineer"}},
 	}
 	
-	results := pool.Run(jobs)
+	results := pool.Run(context.Background(), jobs)
 	
 	if len(results) != 1 {
 		t.Fatalf("Expected 1 result, got %d", len(results))
@@ -94,7 +98,7 @@ func TestWorkerPoolConcurrency(t *testing.T) {
 	}
 	
 	start := time.Now()
-	results := pool.Run(jobs)
+	results := pool.Run(context.Background(), jobs)
 	duration := time.Since(start)
 	
 	// Should complete in roughly 1 second (parallel execution) rather than 2 seconds (sequential)
@@ -153,3 +157,103 @@ func TestResultStruct(t *testing.T) {
 		t.Errorf("Expected no error, got %v", result.Error)
 	}
 }
+
+// server that holds every request until the client gives up
+func newSlowServer(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestWorkerPoolCancelledContext(t *testing.T) {
+	pool := NewWorkerPool(2, 5*time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	jobs := []Job{
+		{BusinessName: "Company 1", URL: "http://127.0.0.1:1", Titles: []string{"engineer"}},
+		{BusinessName: "Company 2", URL: "http://127.0.0.1:1", Titles: []string{"developer"}},
+		{BusinessName: "Company 3", URL: "http://127.0.0.1:1", Titles: []string{"designer"}},
+	}
+	results := pool.Run(ctx, jobs)
+
+	if len(results) != len(jobs) {
+		t.Fatalf("Expected %d results, got %d", len(jobs), len(results))
+	}
+	for _, res := range results {
+		if res.Status != StatusCancelled {
+			t.Errorf("%s: expected status %s, got %s", res.BusinessName, StatusCancelled, res.Status)
+		}
+		if !errors.Is(res.Error, context.Canceled) {
+			t.Errorf("%s: expected context.Canceled, got %v", res.BusinessName, res.Error)
+		}
+	}
+}
+
+func TestWorkerPoolJobTimeout(t *testing.T) {
+	srv := newSlowServer(t)
+	pool := NewWorkerPool(1, 50*time.Millisecond)
+
+	start := time.Now()
+	results := pool.Run(context.Background(), []Job{{BusinessName: "Slow Company", URL: srv.URL}})
+
+	if time.Since(start) > 2*time.Second {
+		t.Errorf("Job timeout was not enforced, took %v", time.Since(start))
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	// only this site ran out of time, the search itself is fine
+	if results[0].Status != StatusFailed {
+		t.Errorf("Expected status %s, got %s", StatusFailed, results[0].Status)
+	}
+}
+
+func TestWorkerPoolSearchTimeout(t *testing.T) {
+	srv := newSlowServer(t)
+	pool := NewWorkerPool(1, 5*time.Second)
+	pool.SearchTimeout = 50 * time.Millisecond
+
+	jobs := []Job{
+		{BusinessName: "Company 1", URL: srv.URL},
+		{BusinessName: "Company 2", URL: srv.URL},
+	}
+
+	start := time.Now()
+	results := pool.Run(context.Background(), jobs)
+
+	if time.Since(start) > 2*time.Second {
+		t.Errorf("Search timeout was not enforced, took %v", time.Since(start))
+	}
+	if len(results) != len(jobs) {
+		t.Fatalf("Expected %d results, got %d", len(jobs), len(results))
+	}
+	for _, res := range results {
+		if res.Status != StatusCancelled || !errors.Is(res.Error, context.DeadlineExceeded) {
+			t.Errorf("%s: expected cancelled by deadline, got %s (%v)", res.BusinessName, res.Status, res.Error)
+		}
+	}
+}
+
+func TestWorkerPoolNotHiringStatus(t *testing.T) {
+	_, srv := newTestSite(t, map[string]string{
+		"/":        `<html><body><a href="/careers">Careers</a></body></html>`,
+		"/careers": `<html><body><h1>Careers</h1><p>We are not currently hiring</p></body></html>`,
+	})
+	pool := NewWorkerPool(1, 5*time.Second)
+	pool.Scraper = newTestScraper(srv)
+
+	results := pool.Run(context.Background(), []Job{{BusinessName: "Diner", URL: srv.URL + "/", Titles: []string{"cook"}}})
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	if results[0].Status != StatusNotHiring || results[0].JobPage != srv.URL+"/careers" {
+		t.Errorf("Expected not_hiring with the careers page, got %s %q", results[0].Status, results[0].JobPage)
+	}
+}
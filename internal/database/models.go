@@ -30,17 +30,68 @@ type Business struct {
 	URL          string             `bson:"url" json:"url"`
 	Lat          float64            `bson:"lat" json:"lat"`
 	Lon          float64            `bson:"lon" json:"lon"`
+	// where URL ended up and the urls that redirected on the way, empty when it did not redirect
+	FinalURL     string             `bson:"final_url,omitempty" json:"final_url,omitempty"`
+	Redirects    []string           `bson:"redirects,omitempty" json:"redirects,omitempty"`
+	// SiteParked, SiteSocialOnly or SiteDead for a broken web presence, empty for a working site
+	SiteFlag     string             `bson:"site_flag,omitempty" json:"site_flag,omitempty"`
 }
 
+// Business.SiteFlag values
+const (
+	SiteParked     = "parked"
+	SiteSocialOnly = "social_only"
+	SiteDead       = "dead"
+)
+
 type Job struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	BusinessID  primitive.ObjectID `bson:"business_id" json:"business_id"`
+	// zero until saved, postings travel inside search results before they reach mongo
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitzero"`
+	BusinessID  primitive.ObjectID `bson:"business_id" json:"business_id,omitzero"`
 	Title       string             `bson:"title" json:"title"`
 	Description string             `bson:"description" json:"description"`
+	Location    string             `bson:"location,omitempty" json:"location,omitempty"`
 	URL         string             `bson:"url" json:"url"`
+	ATSVendor   string             `bson:"ats_vendor,omitempty" json:"ats_vendor,omitempty"`
+	ATSBoard    string             `bson:"ats_board,omitempty" json:"ats_board,omitempty"`
+	// from schema.org JobPosting markup when the page has it
+	Company        string     `bson:"company,omitempty" json:"company,omitempty"`
+	EmploymentType string     `bson:"employment_type,omitempty" json:"employment_type,omitempty"`
+	Salary         *Salary    `bson:"salary,omitempty" json:"salary,omitempty"`
+	ValidThrough   *time.Time `bson:"valid_through,omitempty" json:"valid_through,omitempty"`
+	// classifier confidence for the page the job was found on, and why
+	Score   float64  `bson:"score,omitempty" json:"score,omitempty"`
+	Reasons []string `bson:"reasons,omitempty" json:"reasons,omitempty"`
+	// JobStatusNotHiring when the careers page says there is nothing open, empty for real openings
+	Status  string   `bson:"status,omitempty" json:"status,omitempty"`
+	// the careers page itself, saved for a result without postings, rather than a posting found on it
+	Page    bool     `bson:"page,omitempty" json:"page,omitempty"`
 	PostedAt    *time.Time         `bson:"posted_at,omitempty" json:"posted_at,omitempty"`
 }
 
+// Job.Status for a careers page with no openings
+const JobStatusNotHiring = "not_hiring"
+
+// pay range as published, Unit is HOUR, YEAR... Min == Max for a single figure
+type Salary struct {
+	Currency string  `bson:"currency,omitempty" json:"currency,omitempty"`
+	Min      float64 `bson:"min,omitempty" json:"min,omitempty"`
+	Max      float64 `bson:"max,omitempty" json:"max,omitempty"`
+	Unit     string  `bson:"unit,omitempty" json:"unit,omitempty"`
+}
+
+// what the last scrape learned about a business domain, so the next one can go straight to its careers page
+type DomainRecord struct {
+	// host without www., e.g. "riverside.example"
+	Domain     string    `bson:"domain" json:"domain"`
+	CareersURL string    `bson:"careers_url,omitempty" json:"careers_url,omitempty"`
+	ATSVendor  string    `bson:"ats_vendor,omitempty" json:"ats_vendor,omitempty"`
+	ATSBoard   string    `bson:"ats_board,omitempty" json:"ats_board,omitempty"`
+	CheckedAt  time.Time `bson:"checked_at" json:"checked_at"`
+	// how that scrape went: found, not_hiring, not_found, failed or robots_blocked
+	Outcome    string    `bson:"outcome" json:"outcome"`
+}
+
 type JobResult struct {
 	ID          primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
 	UserID      primitive.ObjectID   `bson:"user_id" json:"user_id"`
@@ -56,7 +56,7 @@ func (r *JobResultRepository) GetLatestJobResults(userID primitive.ObjectID) (*J
 	defer cancel()
 
 	filter := bson.M{"user_id": userID}
-	opts := options.FindOne().SetSort(bson.D{{"created_at", -1}})
+	opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}})
 
 	var jobResult JobResult
 	err := r.collection.FindOne(ctx, filter, opts).Decode(&jobResult)
@@ -75,7 +75,7 @@ func (r *JobResultRepository) GetAllJobResults(userID primitive.ObjectID) ([]Job
 	defer cancel()
 
 	filter := bson.M{"user_id": userID}
-	opts := options.Find().SetSort(bson.D{{"created_at", -1}})
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
 
 	cursor, err := r.collection.Find(ctx, filter, opts)
 	if err != nil {
@@ -232,3 +232,43 @@ func (r *GeoResultRepository) SaveGeoResult(userID primitive.ObjectID, zip strin
 	geoResult.ID = result.InsertedID.(primitive.ObjectID)
 	return geoResult, nil
 }
+
+type DomainRepository struct {
+	*Repository
+	collection *mongo.Collection
+}
+
+func NewDomainRepository(repo *Repository) *DomainRepository {
+	return &DomainRepository{
+		Repository: repo,
+		collection: repo.client.GetCollection("domains"),
+	}
+}
+
+// the record for domain, nil when it was never scraped
+func (r *DomainRepository) Get(ctx context.Context, domain string) (*DomainRecord, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var rec DomainRecord
+	err := r.collection.FindOne(ctx, bson.M{"domain": domain}).Decode(&rec)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get domain %s: %w", domain, err)
+	}
+	return &rec, nil
+}
+
+// insert or replace the record for rec.Domain
+func (r *DomainRepository) Put(ctx context.Context, rec DomainRecord) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := r.collection.ReplaceOne(ctx, bson.M{"domain": rec.Domain}, rec, opts); err != nil {
+		return fmt.Errorf("failed to save domain %s: %w", rec.Domain, err)
+	}
+	return nil
+}
@@ -2,16 +2,14 @@ package server
 
 import (
 	"encoding/json"
-	"fmt"
+	"log"
 	"net/http"
-	"strconv"
-	"strings"
-
-	"github.com/google/uuid"
+	"os"
+	"path/filepath"
 
 	"cliscraper/internal/backend/geo"
-	"cliscraper/internal/utils"
 	"cliscraper/internal/backend/web"
+	"cliscraper/internal/utils"
 )
 
 type Response struct {
@@ -20,6 +18,55 @@ type Response struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
+// searches for the file-based router, rebuilt with the configured geocoder by NewRouter
+var searches = NewSearchManager(geo.DefaultGeocoder(), saveResultsToFile)
+
+// one scraper for every search so robots.txt is fetched once per host, user agent comes from SCRAPER_USER_AGENT
+var sharedScraper = newSharedScraper()
+
+// the default politeness limits are held across searches, a search's own limits only make it gentler
+func newSharedScraper() *web.Scraper {
+	s := web.NewScraperFromEnv()
+	s.Limiter = web.NewLimiter(web.DefaultLimits)
+	return s
+}
+
+// detector profiles searches can pick from, the built-in default plus DETECTOR_PROFILES
+var detectorProfiles = loadDetectorProfiles()
+
+// DETECTOR_PROFILES names a JSON profile or a directory of them, a broken one leaves just the default
+func loadDetectorProfiles() web.Profiles {
+	profiles, err := web.LoadProfiles(os.Getenv("DETECTOR_PROFILES"))
+	if err != nil {
+		log.Printf("Invalid detector profiles, using the default only: %v", err)
+		return web.Profiles{web.DefaultProfileName: web.DefaultProfile()}
+	}
+	return profiles
+}
+
+// page cache under the output directory unless the environment says otherwise, nil when it is off
+func configureCache() *web.HTTPCache {
+	c, err := web.NewHTTPCacheFromEnv(filepath.Join(outputDir, "cache"))
+	if err != nil {
+		log.Printf("Invalid cache configuration, caching disabled: %v", err)
+		return nil
+	}
+	return c
+}
+
+// pick the geocoder backend from the GEOCODER env var, keeping the default when it is misconfigured
+func configureGeocoder() geo.Geocoder {
+	if offline, err := geo.NewOfflineGeocoder(); err == nil && !offline.Complete() {
+		log.Printf("Bundled ZIP dataset has only %d ZIPs, most lookups will fall through to the next geocoder. Run `make geo-data` to bundle the full table", offline.Len())
+	}
+	g, err := geo.NewGeocoderFromEnv()
+	if err != nil {
+		log.Printf("Invalid geocoder configuration, using default: %v", err)
+		return geo.DefaultGeocoder()
+	}
+	return g
+}
+
 func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -31,146 +78,105 @@ func HealthHandler(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, Response{Status: "ok"})
 }
 
-// scrape/search trigger
+// scrape/search trigger, runs the search to completion before responding. the returned id can be looked up on /searches/{id}
 func SearchHandler(w http.ResponseWriter, r *http.Request) {
-    zip := r.URL.Query().Get("zip")
-    radiusStr := r.URL.Query().Get("radius")
-    title := r.URL.Query().Get("title")
+	p, err := parseSearchParams(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{
+			Status:  "error",
+			Message: err.Error(),
+		})
+		return
+	}
 
-    radius, err := strconv.Atoi(radiusStr)
-    if err != nil {
-        writeJSON(w, http.StatusBadRequest, Response{
-            Status:  "error",
-            Message: "invalid radius",
-        })
-        return
-    }
+	st := searches.Run(r.Context(), p).Snapshot()
+	if st.Status == utils.SearchFailed {
+		writeJSON(w, http.StatusInternalServerError, Response{
+			Status:  "error",
+			Message: st.Error,
+		})
+		return
+	}
 
-    // step 1: find businesses by ZIP
-    businesses, err := geo.FindBusinessesByZip(zip, radius)
-    if err != nil {
-        // "no input slice" case as no results, not failure
-        if strings.Contains(err.Error(), "must provide at least one element in input slice") ||
-            strings.Contains(strings.ToLower(err.Error()), "no businesses found") {
-            writeJSON(w, http.StatusOK, Response{
-                Status:  "ok",
-                Message: "no businesses found in specified area",
-                Data: map[string]interface{}{
-                    "zip":     zip,
-                    "radius":  radius,
-                    "title":   title,
-                    "results": []utils.JobPageResult{},
-                },
-            })
-            return
-        }
-
-        // otherwise, it's a real server error
-        writeJSON(w, http.StatusInternalServerError, Response{
-            Status:  "error",
-            Message: fmt.Sprintf("failed to locate businesses: %v", err),
-        })
-        return
-    }
+	data := map[string]interface{}{
+		"id":      st.ID,
+		"zip":     st.Zip,
+		"radius":  st.Radius,
+		"title":   st.Title,
+		"results": st.Results,
+	}
+	addDeduped(data, st)
+	addFailures(data, st)
+	addSites(data, st)
+	addCache(data, st)
 
-    // if no businesses exit cleanly
-    if len(businesses) == 0 {
-        writeJSON(w, http.StatusOK, Response{
-            Status:  "ok",
-            Message: "no businesses found in specified area",
-            Data: map[string]interface{}{
-                "zip":     zip,
-                "radius":  radius,
-                "title":   title,
-                "results": []utils.JobPageResult{},
-            },
-        })
-        return
-    }
+	// always return ok with structured data, even if results are empty
+	writeJSON(w, http.StatusOK, Response{
+		Status:  "ok",
+		Message: st.Message,
+		Data:    data,
+	})
+}
 
-    // step 2: prepare jobs
-    jobs := make([]web.Job, 0, len(businesses))
-    for _, b := range businesses {
-        if b.URL == "" {
-            continue
-        }
-        jobs = append(jobs, web.Job{
-            BusinessName: b.Name,
-            URL:          b.URL,
-            Titles:       []string{title},
-        })
-    }
+// how many businesses shared a site scraped for an earlier one, left out when none did
+func addDeduped(data map[string]interface{}, st utils.SearchStatus) {
+	if st.Counts.Deduped == 0 {
+		return
+	}
+	data["deduped"] = st.Counts.Deduped
+}
 
-    // edge case where all businesses had no url
-    if len(jobs) == 0 {
-        writeJSON(w, http.StatusOK, Response{
-            Status:  "ok",
-            Message: "businesses found, but none have valid URLs",
-            Data: map[string]interface{}{
-                "zip":     zip,
-                "radius":  radius,
-                "title":   title,
-                "results": []utils.JobPageResult{},
-            },
-        })
-        return
-    }
+// failure summary and failed sites, left out when every site could be scraped.
+// POST /searches/{id}/retry re-runs the failed ones
+func addFailures(data map[string]interface{}, st utils.SearchStatus) {
+	if len(st.Failed) == 0 {
+		return
+	}
+	data["failures"] = st.Failures
+	data["failed"] = st.Failed
+}
 
-    // step 3: run worker pool
-    pool := web.NewWorkerPool(100, 300)
-    results := pool.Run(jobs)
-
-    // step 4: collect successful results
-    jobResults := make([]utils.JobPageResult, 0, len(results))
-    for _, res := range results {
-        if res.Error != nil {
-            fmt.Printf("Error scraping %s: %v\n", res.URL, res.Error)
-            continue
-        }
-        if res.JobPage != "" {
-            jobResults = append(jobResults, utils.JobPageResult{
-                BusinessName: res.BusinessName,
-                URL:          res.JobPage,
-            })
-        }
-    }
+// websites that redirected or have a broken web presence, left out when there are none
+func addSites(data map[string]interface{}, st utils.SearchStatus) {
+	if len(st.Sites) == 0 {
+		return
+	}
+	data["sites"] = st.Sites
+}
 
-    // step 5: save only if there are valid results
-    outDir := "./output"
-    if len(jobResults) > 0 {
-        if err := utils.WriteResults(jobResults, outDir); err != nil {
-            writeJSON(w, http.StatusInternalServerError, Response{
-                Status:  "error",
-                Message: fmt.Sprintf("failed to save results: %v", err),
-            })
-            return
-        }
-    }
+// page cache use, left out when the cache is off
+func addCache(data map[string]interface{}, st utils.SearchStatus) {
+	if st.Cache == (utils.CacheCounts{}) {
+		return
+	}
+	data["cache"] = st.Cache
+}
 
-    id := uuid.New().String()
-
-    // always return ok with structured data, even if results are empty
-    writeJSON(w, http.StatusOK, Response{
-        Status: "ok",
-        Data: map[string]interface{}{
-            "id":      id,
-            "zip":     zip,
-            "radius":  radius,
-            "title":   title,
-            "results": jobResults,
-        },
-    })
+// GET /profiles -- detector profiles a search can pick with ?profile=
+func ProfilesHandler(w http.ResponseWriter, r *http.Request) {
+	names := detectorProfiles.Names()
+	profiles := make([]*web.Profile, 0, len(names))
+	for _, name := range names {
+		profiles = append(profiles, detectorProfiles[name])
+	}
+	writeJSON(w, http.StatusOK, Response{
+		Status: "ok",
+		Data: map[string]interface{}{
+			"default":  web.DefaultProfileName,
+			"profiles": profiles,
+		},
+	})
 }
 
 // fetch search results by latest file
 func ResultsHandler(w http.ResponseWriter, r *http.Request) {
     // NOTE: ignoring {id}, just load the latest results.json
-    outDir := "./output"
-    results, err := utils.LoadLatestResults(outDir)
+    results, err := utils.LoadLatestResults(outputDir)
     if err != nil {
         writeJSON(w, http.StatusNotFound, Response{Status: "error", Message: "results not found"})
         return
     }
+	results = utils.FilterByATS(results, r.URL.Query().Get("ats"))
 	writeJSON(w, http.StatusOK, Response{
 		Status: "ok",
 		Data:   map[string]interface{}{
@@ -4,16 +4,14 @@ import (
 	//"encoding/json"
 	"fmt"
 	"net/http"
-	"strconv"
 
-	"cliscraper/internal/backend/geo"
 	"cliscraper/internal/utils"
-	"cliscraper/internal/backend/web"
 	//"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type DatabaseHandlers struct {
 	dbManager *utils.DatabaseManager
+	searches  *SearchManager
 }
 
 func NewDatabaseHandlers() (*DatabaseHandlers, error) {
@@ -22,9 +20,11 @@ func NewDatabaseHandlers() (*DatabaseHandlers, error) {
 		return nil, fmt.Errorf("failed to create database manager: %w", err)
 	}
 
-	return &DatabaseHandlers{
-		dbManager: dbManager,
-	}, nil
+	h := &DatabaseHandlers{dbManager: dbManager}
+	h.searches = NewSearchManager(configureGeocoder(), h.saveResults)
+	h.searches.rememberDomains(dbManager.Domains())
+	h.searches.useCache(configureCache())
+	return h, nil
 }
 
 func (h *DatabaseHandlers) Close() error {
@@ -32,109 +32,61 @@ func (h *DatabaseHandlers) Close() error {
 }
 
 func (h *DatabaseHandlers) SearchHandlerDB(w http.ResponseWriter, r *http.Request) {
-	zip := r.URL.Query().Get("zip")
-	radiusStr := r.URL.Query().Get("radius")
-	title := r.URL.Query().Get("title")
-
-	radius, err := strconv.Atoi(radiusStr)
+	p, err := parseSearchParams(r)
 	if err != nil {
-		writeJSON(w, http.StatusBadRequest, Response{Status: "error", Message: "invalid radius"})
+		writeJSON(w, http.StatusBadRequest, Response{Status: "error", Message: err.Error()})
 		return
 	}
 
 	userID := utils.GetDefaultUserID()
 
-	// step 1: find businesses by zip
-	businesses, err := geo.FindBusinessesByZip(zip, radius)
-	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, Response{Status: "error", Message: err.Error()})
+	st := h.searches.Run(r.Context(), p).Snapshot()
+	if st.Status == utils.SearchFailed {
+		writeJSON(w, http.StatusInternalServerError, Response{Status: "error", Message: st.Error})
 		return
 	}
 
-	// if no businesses found, return early with a helpful message
-	if len(businesses) == 0 {
-		writeJSON(w, http.StatusOK, Response{
-			Status: "ok",
-			Data: map[string]interface{}{
-				"user_id":  userID.Hex(),
-				"zip":      zip,
-				"radius":   radius,
-				"title":    title,
-				"message":  "No businesses found in the specified area",
-				"results":  []interface{}{},
-			},
-		})
-		return
+	data := map[string]interface{}{
+		"id":      st.ID,
+		"user_id": userID.Hex(),
+		"zip":     st.Zip,
+		"radius":  st.Radius,
+		"title":   st.Title,
+		"results": st.Results,
 	}
-
-	// step 2: create workers and prepare jobs for pooling
-	jobs := []web.Job{}
-	for _, b := range businesses {
-		if b.URL == "" {
-			continue
-		}
-		jobs = append(jobs, web.Job{
-			BusinessName: b.Name,
-			URL:          b.URL,
-			Titles:       []string{title},
-		})
+	if st.Message != "" {
+		data["message"] = st.Message
 	}
+	addDeduped(data, st)
+	addFailures(data, st)
+	addSites(data, st)
+	addCache(data, st)
 
-	// step 3: run worker pool
-	pool := web.NewWorkerPool(100, 300) // x workers, x s timeout
-	results := pool.Run(jobs)
+	writeJSON(w, http.StatusOK, Response{Status: "ok", Data: data})
+}
 
-	// step 4: collect results
-	jobResults := []utils.JobPageResult{}
-	for _, res := range results {
-		if res.Error != nil {
-			fmt.Printf("Error scraping %s: %v\n", res.URL, res.Error)
-			continue
-		}
-		if res.JobPage != "" {
-			jobResults = append(jobResults, utils.JobPageResult{
-				BusinessName: res.BusinessName,
-				URL:          res.JobPage,
-				Description:  "", // if available
-			})
+// persist hook for searches in database mode
+func (h *DatabaseHandlers) saveResults(p SearchParams, results []utils.JobPageResult, sites []utils.SiteReport) error {
+	userID := utils.GetDefaultUserID()
+
+	// store results in MongoDB, InsertMany refuses empty slices so skip when nothing was found
+	if len(results) > 0 {
+		if err := h.dbManager.WriteResultsToDB(userID, p.Title, results); err != nil {
+			return err
 		}
 	}
 
-	// step 5: store results in MongoDB
-	if err := h.dbManager.WriteResultsToDB(userID, title, jobResults); err != nil {
-		writeJSON(w, http.StatusInternalServerError, Response{Status: "error", Message: err.Error()})
-		return
+	// businesses with a parked, social-only or dead website
+	if err := h.dbManager.WriteSiteReportsToDB(sites); err != nil {
+		return err
 	}
 
-	// step 6: save geo result
-	_, err = h.dbManager.WriteGeoResultsToDB(userID, zip, radius)
-	if err != nil {
+	// save geo result
+	if _, err := h.dbManager.WriteGeoResultsToDB(userID, p.Zip, p.Radius); err != nil {
 		fmt.Printf("Warning: failed to save geo result: %v\n", err)
-		// don't fail the request for this
+		// don't fail the search for this
 	}
-
-	// convert worker pool results to JobPageResult format for API compatibility
-	jobPageResults := make([]utils.JobPageResult, 0, len(results))
-	for _, res := range results {
-		if res.Error == nil && res.JobPage != "" {
-			jobPageResults = append(jobPageResults, utils.JobPageResult{
-				BusinessName: res.BusinessName,
-				URL:          res.JobPage,
-				Description:  "", // no description available from worker pool
-			})
-		}
-	}
-
-	writeJSON(w, http.StatusOK, Response{
-		Status: "ok",
-		Data: map[string]interface{}{
-			"user_id": userID.Hex(),
-			"zip":     zip,
-			"radius":  radius,
-			"title":   title,
-			"results": jobPageResults,
-		},
-	})
+	return nil
 }
 
 // handle results requests with MongoDB retrieval
@@ -151,6 +103,7 @@ func (h *DatabaseHandlers) ResultsHandlerDB(w http.ResponseWriter, r *http.Reque
 		return
 	}
 	fmt.Printf("Loaded %d results\n", len(results))
+	results = utils.FilterByATS(results, r.URL.Query().Get("ats"))
 
 	writeJSON(w, http.StatusOK, Response{
 		Status: "ok",
@@ -107,6 +107,36 @@ func TestResultsHandler(t *testing.T) {
 	}
 }
 
+func TestProfilesHandler(t *testing.T) {
+	req := httptest.NewRequest("GET", "/profiles", nil)
+	w := httptest.NewRecorder()
+
+	ProfilesHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response struct {
+		Data struct {
+			Default  string `json:"default"`
+			Profiles []struct {
+				Name          string `json:"name"`
+				ContextWindow int    `json:"context_window"`
+			} `json:"profiles"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Data.Default != "default" || len(response.Data.Profiles) == 0 || response.Data.Profiles[0].Name != "default" {
+		t.Errorf("Expected the default profile first, got %+v", response.Data)
+	}
+	if response.Data.Profiles[0].ContextWindow == 0 {
+		t.Error("Expected profile settings in the response")
+	}
+}
+
 func TestStarredHandler(t *testing.T) {
 	req := httptest.NewRequest("GET", "/starred", nil)
 	w := httptest.NewRecorder()
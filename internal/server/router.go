@@ -4,14 +4,20 @@ package server
 import (
 	"fmt"
 	"net/http"
+	"path/filepath"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+
+	"cliscraper/internal/backend/web"
 )
 
 // set up all routes for the API server
 func NewRouter() http.Handler {
 	r := chi.NewRouter()
+	searches = NewSearchManager(configureGeocoder(), saveResultsToFile)
+	searches.rememberDomains(web.NewFileDomainStore(filepath.Join(outputDir, "domains.json")))
+	searches.useCache(configureCache())
 
 	// middleware probablt want logging, recovery, etc, can adjust later 
 	r.Use(middleware.Logger)
@@ -21,7 +27,14 @@ func NewRouter() http.Handler {
 	// API routes -- should work for the things we have implemented so far 
 	r.Get("/health", HealthHandler)
 	r.Get("/search", SearchHandler)
+	r.Post("/searches", searches.CreateSearchHandler)
+	r.Get("/searches/{id}", searches.GetSearchHandler)
+	r.Delete("/searches/{id}", searches.CancelSearchHandler)
+	r.Get("/searches/{id}/events", searches.SearchEventsHandler)
+	r.Post("/searches/{id}/retry", searches.RetrySearchHandler)
+	r.Delete("/admin/cache", searches.PurgeCacheHandler)
 	r.Get("/results", ResultsHandler)
+	r.Get("/profiles", ProfilesHandler)
 	r.Get("/starred", StarredHandler)
 
 	return r
@@ -54,7 +67,14 @@ func NewDatabaseRouter() (http.Handler, error) {
 	// API routes with database handlers
 	r.Get("/health", HealthHandler)
 	r.Get("/search", dbHandlers.SearchHandlerDB)
+	r.Post("/searches", dbHandlers.searches.CreateSearchHandler)
+	r.Get("/searches/{id}", dbHandlers.searches.GetSearchHandler)
+	r.Delete("/searches/{id}", dbHandlers.searches.CancelSearchHandler)
+	r.Get("/searches/{id}/events", dbHandlers.searches.SearchEventsHandler)
+	r.Post("/searches/{id}/retry", dbHandlers.searches.RetrySearchHandler)
+	r.Delete("/admin/cache", dbHandlers.searches.PurgeCacheHandler)
 	r.Get("/results", dbHandlers.ResultsHandlerDB)
+	r.Get("/profiles", ProfilesHandler)
 	r.Get("/starred", dbHandlers.StarredHandlerDB)
 
 	return r, nil
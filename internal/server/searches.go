@@ -0,0 +1,784 @@
+// background search jobs. every search gets a real id that can be polled and cancelled while the scrape runs
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"cliscraper/internal/backend/geo"
+	"cliscraper/internal/backend/web"
+	"cliscraper/internal/database"
+	"cliscraper/internal/utils"
+)
+
+const (
+	// searches allowed to scrape at the same time, the rest wait in the queue
+	maxConcurrentSearches = 2
+	// how long finished searches stay around for polling
+	searchRetention = time.Hour
+	// comment line sent on idle event streams so proxies keep the connection open
+	sseKeepAlive = 15 * time.Second
+	// directory used by the file-based storage
+	outputDir = "./output"
+
+	// scraper workers per search
+	scrapeWorkers = 100
+	// time allowed for a single site, including the careers page hop
+	siteTimeout = 45 * time.Second
+	// hard stop for the scraping stage of a search, whatever is left is reported as cancelled
+	scrapeTimeout = 20 * time.Minute
+
+	// longest host_delay_ms a search can ask for. the other bounds are web.DefaultLimits, which every search shares
+	maxHostDelay = 60000 // ms
+)
+
+type SearchParams struct {
+	Zip    string
+	Radius int
+	Title  string
+	// Title parsed as a boolean query, nil when the title is blank
+	Query *web.TitleQuery
+	// politeness limits for the scrape, web.DefaultLimits unless the request overrides them
+	Limits web.Limits
+	// pages the classifier scores below this are not reported, 0 reports every match
+	MinScore float64
+	// detector profile picked with ?profile=, nil uses the default
+	Profile *web.Profile
+	// set for retries: the sites to scrape instead of geocoding Zip, and the search they failed in
+	Sites   []web.Job
+	RetryOf string
+}
+
+// read zip/radius/title from the query string or a form body, the title is parsed as a boolean query
+func parseSearchParams(r *http.Request) (SearchParams, error) {
+	radius, err := strconv.Atoi(r.FormValue("radius"))
+	if err != nil || radius < 0 {
+		return SearchParams{}, fmt.Errorf("invalid radius")
+	}
+
+	zip := strings.TrimSpace(r.FormValue("zip"))
+	if !utils.IsValidZip(zip) {
+		return SearchParams{}, fmt.Errorf("invalid zip")
+	}
+
+	title := strings.TrimSpace(r.FormValue("title"))
+	query, err := web.ParseTitleQuery(title)
+	if err != nil {
+		return SearchParams{}, err
+	}
+
+	limits, err := parseLimits(r)
+	if err != nil {
+		return SearchParams{}, err
+	}
+
+	var minScore float64
+	if v := r.FormValue("min_score"); v != "" {
+		minScore, err = strconv.ParseFloat(v, 64)
+		if err != nil || minScore < 0 || minScore > 1 {
+			return SearchParams{}, fmt.Errorf("invalid min_score, expected 0-1")
+		}
+	}
+
+	var profile *web.Profile
+	if name := strings.TrimSpace(r.FormValue("profile")); name != "" {
+		var ok bool
+		if profile, ok = detectorProfiles[name]; !ok {
+			return SearchParams{}, fmt.Errorf("unknown profile %q, see /profiles", name)
+		}
+	}
+
+	return SearchParams{
+		Zip:      zip,
+		Radius:   radius,
+		Title:    title,
+		Query:    query,
+		Limits:   limits,
+		MinScore: minScore,
+		Profile:  profile,
+	}, nil
+}
+
+func (p SearchParams) profileName() string {
+	if p.Profile == nil {
+		return web.DefaultProfileName
+	}
+	return p.Profile.Name
+}
+
+// optional max_per_host, host_delay_ms and rps overrides. every search also waits on the shared web.DefaultLimits,
+// so an override can only make a search gentler than those and anything looser is refused rather than ignored
+func parseLimits(r *http.Request) (web.Limits, error) {
+	limits := web.DefaultLimits
+	maxPerHost := web.DefaultLimits.MaxPerHost
+	minHostDelay := int(web.DefaultLimits.HostInterval / time.Millisecond)
+	maxRequestsPerSecond := web.DefaultLimits.RequestsPerSecond
+
+	if v := r.FormValue("max_per_host"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPerHost {
+			return limits, fmt.Errorf("invalid max_per_host, expected 1-%d", maxPerHost)
+		}
+		limits.MaxPerHost = n
+	}
+	if v := r.FormValue("host_delay_ms"); v != "" {
+		ms, err := strconv.Atoi(v)
+		if err != nil || ms < minHostDelay || ms > maxHostDelay {
+			return limits, fmt.Errorf("invalid host_delay_ms, expected %d-%d", minHostDelay, maxHostDelay)
+		}
+		limits.HostInterval = time.Duration(ms) * time.Millisecond
+	}
+	if v := r.FormValue("rps"); v != "" {
+		rps, err := strconv.ParseFloat(v, 64)
+		if err != nil || rps <= 0 || rps > maxRequestsPerSecond {
+			return limits, fmt.Errorf("invalid rps, expected a value above 0 and up to %g", maxRequestsPerSecond)
+		}
+		limits.RequestsPerSecond = rps
+	}
+	return limits, nil
+}
+
+// a single search and its live status
+type Search struct {
+	mu     sync.Mutex
+	params SearchParams
+	status utils.SearchStatus
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// every event so far, replayed to late subscribers, changed is closed and replaced on each publish
+	events  []utils.SearchEvent
+	changed chan struct{}
+}
+
+// copy of the current status, safe to encode while the search keeps running
+func (s *Search) Snapshot() utils.SearchStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.status
+	st.Results = append([]utils.JobPageResult{}, s.status.Results...)
+	st.Failed = append([]utils.FailedSite(nil), s.status.Failed...)
+	st.Sites = append([]utils.SiteReport(nil), s.status.Sites...)
+	if s.status.Failures != nil {
+		st.Failures = make(map[string]int, len(s.status.Failures))
+		for k, n := range s.status.Failures {
+			st.Failures[k] = n
+		}
+	}
+	return st
+}
+
+// apply a change to the status unless the search already finished, ev (optional) is published with the new counts
+func (s *Search) update(fn func(st *utils.SearchStatus), ev *utils.SearchEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status.Finished() {
+		return
+	}
+	fn(&s.status)
+	if ev != nil {
+		s.publishLocked(*ev)
+	}
+}
+
+// move the search into a terminal state and emit the completion event, the first call wins
+func (s *Search) finish(status, message string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status.Finished() {
+		return
+	}
+
+	now := time.Now()
+	s.status.Status = status
+	s.status.FinishedAt = &now
+	if message != "" {
+		s.status.Message = message
+	}
+	if err != nil {
+		s.status.Error = err.Error()
+	}
+	s.publishLocked(utils.SearchEvent{Type: utils.EventComplete, Status: status, Error: s.status.Error})
+}
+
+func (s *Search) publishLocked(ev utils.SearchEvent) {
+	ev.Seq = len(s.events) + 1
+	ev.Counts = s.status.Counts
+	s.events = append(s.events, ev)
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// events after seq, a channel closed on the next publish, and whether the stream is complete
+func (s *Search) eventsSince(seq int) ([]utils.SearchEvent, <-chan struct{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if seq < 0 {
+		seq = 0
+	}
+	var evs []utils.SearchEvent
+	if seq < len(s.events) {
+		evs = append(evs, s.events[seq:]...)
+	}
+	return evs, s.changed, s.status.Finished()
+}
+
+// Done is closed once the search goroutine has returned
+func (s *Search) Done() <-chan struct{} {
+	return s.done
+}
+
+// called with the scraped results once a search completes, file and database modes plug in here
+// sites are the websites that redirected or were flagged, see utils.SiteReport
+type persistFunc func(p SearchParams, results []utils.JobPageResult, sites []utils.SiteReport) error
+
+type SearchManager struct {
+	mu       sync.RWMutex
+	searches map[string]*Search
+	geocoder geo.Geocoder
+	persist  persistFunc
+	scraper  *web.Scraper
+	slots    chan struct{}
+}
+
+func NewSearchManager(g geo.Geocoder, persist persistFunc) *SearchManager {
+	return &SearchManager{
+		searches: make(map[string]*Search),
+		geocoder: g,
+		persist:  persist,
+		scraper:  sharedScraper,
+		slots:    make(chan struct{}, maxConcurrentSearches),
+	}
+}
+
+// scrape with a copy of the scraper that remembers careers pages per domain in store
+func (m *SearchManager) rememberDomains(store web.DomainStore) {
+	s := *m.scraper
+	s.Domains = store
+	m.scraper = &s
+}
+
+// scrape through an on-disk page cache, nil leaves it off
+func (m *SearchManager) useCache(c *web.HTTPCache) {
+	s := *m.scraper
+	s.Cache = c
+	m.scraper = &s
+}
+
+// queue a search and return immediately
+func (m *SearchManager) Start(p SearchParams) *Search {
+	s, ctx := m.register(context.Background(), p)
+	go m.execute(ctx, s)
+	return s
+}
+
+// run a search to completion, tied to the caller's context
+func (m *SearchManager) Run(ctx context.Context, p SearchParams) *Search {
+	s, ctx := m.register(ctx, p)
+	m.execute(ctx, s)
+	return s
+}
+
+func (m *SearchManager) Get(id string) (*Search, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.searches[id]
+	return s, ok
+}
+
+// stop a queued or running search, finished searches are left untouched
+func (m *SearchManager) Cancel(id string) (*Search, bool) {
+	s, ok := m.Get(id)
+	if !ok {
+		return nil, false
+	}
+	s.finish(utils.SearchCancelled, "search cancelled", nil)
+	s.cancel()
+	return s, true
+}
+
+func (m *SearchManager) register(parent context.Context, p SearchParams) (*Search, context.Context) {
+	ctx, cancel := context.WithCancel(parent)
+	s := &Search{
+		params:  p,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		changed: make(chan struct{}),
+		status: utils.SearchStatus{
+			ID:        uuid.New().String(),
+			Status:    utils.SearchQueued,
+			Zip:       p.Zip,
+			Radius:    p.Radius,
+			Title:     p.Title,
+			Profile:   p.profileName(),
+			RetryOf:   p.RetryOf,
+			Results:   []utils.JobPageResult{},
+			CreatedAt: time.Now(),
+		},
+	}
+
+	m.mu.Lock()
+	m.prune()
+	m.searches[s.status.ID] = s
+	m.mu.Unlock()
+
+	return s, ctx
+}
+
+// drop finished searches past retention, caller holds m.mu
+func (m *SearchManager) prune() {
+	cutoff := time.Now().Add(-searchRetention)
+	for id, s := range m.searches {
+		st := s.Snapshot()
+		if st.FinishedAt != nil && st.FinishedAt.Before(cutoff) {
+			delete(m.searches, id)
+		}
+	}
+}
+
+func (m *SearchManager) execute(ctx context.Context, s *Search) {
+	defer close(s.done)
+	defer s.cancel()
+
+	// wait for a free slot
+	select {
+	case m.slots <- struct{}{}:
+		defer func() { <-m.slots }()
+	case <-ctx.Done():
+		s.finish(utils.SearchCancelled, "search cancelled", nil)
+		return
+	}
+
+	s.update(func(st *utils.SearchStatus) { st.Status = utils.SearchRunning }, nil)
+
+	message, err := m.scrape(ctx, s)
+	switch {
+	case ctx.Err() != nil:
+		s.finish(utils.SearchCancelled, "search cancelled", nil)
+	case err != nil:
+		log.Printf("Search %s failed: %v", s.status.ID, err)
+		s.finish(utils.SearchFailed, "", err)
+	default:
+		s.finish(utils.SearchDone, message, nil)
+	}
+}
+
+// geocode, locate businesses, scrape them and persist the hits. returns an informational message for empty searches
+func (m *SearchManager) scrape(ctx context.Context, s *Search) (string, error) {
+	p := s.params
+
+	jobs := p.Sites
+	if len(jobs) > 0 {
+		// a retry already knows its sites
+		s.update(func(st *utils.SearchStatus) { st.Counts.Businesses = len(jobs) }, nil)
+	} else {
+		var message string
+		var err error
+		if jobs, message, err = m.locate(ctx, s); err != nil || message != "" {
+			return message, err
+		}
+	}
+
+	// chain locations sharing a website are scraped once
+	sites, businesses := groupBySite(jobs)
+	s.update(func(st *utils.SearchStatus) {
+		st.Counts.Sites = len(sites)
+		st.Counts.Deduped = len(jobs) - len(sites)
+	}, &utils.SearchEvent{Type: utils.EventBusinesses})
+
+	return "", m.scrapeSites(ctx, s, sites, businesses)
+}
+
+// one job per site, scraping the canonical url of the first business there, and the businesses behind each scraped url
+func groupBySite(jobs []web.Job) ([]web.Job, map[string][]web.Job) {
+	sites := make([]web.Job, 0, len(jobs))
+	businesses := make(map[string][]web.Job)
+	scraped := make(map[string]string) // site key -> url scraped for it
+	for _, j := range jobs {
+		key := utils.SiteKey(j.URL)
+		u, seen := scraped[key]
+		if !seen {
+			u = utils.CanonicalURL(j.URL)
+			scraped[key] = u
+			site := j
+			site.URL = u
+			sites = append(sites, site)
+		}
+		businesses[u] = append(businesses[u], j)
+	}
+	return sites, businesses
+}
+
+// steps 1 and 2: the sites of the businesses around the zip, with a message instead when there are none
+func (m *SearchManager) locate(ctx context.Context, s *Search) ([]web.Job, string, error) {
+	p := s.params
+
+	// step 1: resolve the zip, then find businesses around it
+	lat, lon, err := m.geocoder.Geocode(p.Zip)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to locate businesses: %w", err)
+	}
+	s.update(func(st *utils.SearchStatus) {}, &utils.SearchEvent{Type: utils.EventGeocoded, Lat: lat, Lon: lon})
+
+	businesses, err := geo.LocateBusinesses(lat, lon, p.Radius)
+	if err != nil {
+		// "no input slice" case as no results, not failure
+		if strings.Contains(err.Error(), "must provide at least one element in input slice") ||
+			strings.Contains(strings.ToLower(err.Error()), "no businesses found") {
+			return nil, "no businesses found in specified area", nil
+		}
+		return nil, "", fmt.Errorf("failed to locate businesses: %w", err)
+	}
+	if len(businesses) == 0 {
+		return nil, "no businesses found in specified area", nil
+	}
+
+	// step 2: prepare jobs
+	jobs := make([]web.Job, 0, len(businesses))
+	for _, b := range businesses {
+		if b.URL == "" {
+			continue
+		}
+		jobs = append(jobs, web.Job{
+			BusinessName: b.Name,
+			URL:          b.URL,
+			Query:        p.Query,
+		})
+	}
+
+	s.update(func(st *utils.SearchStatus) { st.Counts.Businesses = len(businesses) }, nil)
+
+	// edge case where all businesses had no url
+	if len(jobs) == 0 {
+		return nil, "businesses found, but none have valid URLs", nil
+	}
+	return jobs, "", ctx.Err()
+}
+
+// step 3 and 4: scrape the sites and persist the hits. each site's result is reported for every business in businesses[site.URL]
+func (m *SearchManager) scrapeSites(ctx context.Context, s *Search, jobs []web.Job, businesses map[string][]web.Job) error {
+	p := s.params
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	// step 3: run worker pool, publishing partial results as they land
+	jobResults := make([]utils.JobPageResult, 0, len(jobs))
+	var sites []utils.SiteReport
+	pool := web.NewWorkerPool(scrapeWorkers, siteTimeout)
+	pool.SearchTimeout = scrapeTimeout
+	pool.Scraper = m.scraper
+	pool.Limits = p.Limits
+	pool.MinScore = p.MinScore
+	pool.Profile = p.Profile
+	var cacheStats *web.CacheStats
+	if m.scraper.Cache != nil {
+		cacheStats = &web.CacheStats{}
+		pool.CacheStats = cacheStats
+	}
+	// site is false for the second and later businesses sharing a site, the per-site counts are already taken
+	record := func(res web.Result, site bool) {
+		var hit *utils.JobPageResult
+		var failed *utils.FailedSite
+		var report *utils.SiteReport
+		if res.Site.Flag != "" || len(res.Site.Redirects) > 0 {
+			report = &utils.SiteReport{
+				Business:  res.BusinessName,
+				URL:       res.URL,
+				FinalURL:  res.Site.FinalURL,
+				Redirects: res.Site.Redirects,
+				Flag:      string(res.Site.Flag),
+			}
+			sites = append(sites, *report)
+		}
+		if res.Failure != "" {
+			failed = &utils.FailedSite{Business: res.BusinessName, URL: res.URL, Kind: string(res.Failure)}
+			if res.Error != nil {
+				failed.Error = res.Error.Error()
+			}
+			if res.Status == web.StatusFailed {
+				log.Printf("Error scraping %s (%s): %v", res.URL, res.Failure, res.Error)
+			}
+		} else if res.Status == web.StatusFound || res.Status == web.StatusNotHiring {
+			hit = &utils.JobPageResult{
+				BusinessName: res.BusinessName,
+				URL:          res.JobPage,
+				ATSVendor:    res.ATSVendor,
+				ATSBoard:     res.ATSBoard,
+				ATSName:      web.ATSName(res.ATSVendor),
+				Jobs:         res.Jobs,
+				Score:        res.Score,
+				Reasons:      res.Reasons,
+				Documents:    res.Documents,
+				FinalURL:     res.Site.FinalURL,
+				Redirects:    res.Site.Redirects,
+			}
+			// kept as a result so the careers page is not lost, but not counted as found
+			if res.Status == web.StatusNotHiring {
+				hit.Status = database.JobStatusNotHiring
+			}
+			jobResults = append(jobResults, *hit)
+		}
+
+		s.update(func(st *utils.SearchStatus) {
+			if site {
+				st.Counts.Scanned++
+				switch res.Status {
+				case web.StatusFailed:
+					st.Counts.Errors++
+				case web.StatusCancelled:
+					st.Counts.Cancelled++
+				case web.StatusBlocked:
+					st.Counts.Blocked++
+				}
+				if res.Probes > 0 {
+					st.Counts.AddProbe(res.ProbeHit)
+				}
+				if res.Remembered {
+					st.Counts.Remembered++
+				}
+			}
+			if cacheStats != nil {
+				st.Cache = cacheCounts(cacheStats)
+			}
+			if failed != nil {
+				if st.Failures == nil {
+					st.Failures = make(map[string]int)
+				}
+				st.Failures[failed.Kind]++
+				st.Failed = append(st.Failed, *failed)
+			}
+			if report != nil {
+				if report.Flag != "" {
+					st.Counts.Flagged++
+				}
+				st.Sites = append(st.Sites, *report)
+			}
+		}, scrapedEvent(res))
+
+		if hit != nil {
+			s.update(func(st *utils.SearchStatus) {
+				if hit.NotHiring() {
+					st.Counts.NotHiring++
+				} else {
+					st.Counts.Found++
+				}
+				st.Results = append(st.Results, *hit)
+			}, &utils.SearchEvent{Type: utils.EventHit, Business: hit.BusinessName, URL: hit.URL, Result: hit})
+		}
+	}
+	pool.OnResult = func(res web.Result) {
+		for i, b := range businesses[res.URL] {
+			r := res
+			r.BusinessName, r.URL = b.BusinessName, b.URL
+			record(r, i == 0)
+		}
+	}
+	pool.Run(ctx, jobs)
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	// step 4: save only if there are valid results
+	if m.persist != nil {
+		if err := m.persist(p, jobResults, sites); err != nil {
+			return fmt.Errorf("failed to save results: %w", err)
+		}
+	}
+	return nil
+}
+
+func cacheCounts(cs *web.CacheStats) utils.CacheCounts {
+	return utils.CacheCounts{
+		Hits:        int(cs.Hits.Load()),
+		Revalidated: int(cs.Revalidated.Load()),
+		Misses:      int(cs.Misses.Load()),
+	}
+}
+
+func scrapedEvent(res web.Result) *utils.SearchEvent {
+	ev := &utils.SearchEvent{Type: utils.EventScraped, Business: res.BusinessName, URL: res.URL, Failure: string(res.Failure)}
+	if res.Error != nil {
+		ev.Error = res.Error.Error()
+	}
+	return ev
+}
+
+var (
+	errSearchNotFound = errors.New("search not found")
+	errSearchRunning  = errors.New("search is still running")
+	errNothingFailed  = errors.New("no failed sites to retry")
+)
+
+// start a search re-running the failed sites of a finished one with the same parameters.
+// robots-blocked, non-html and oversized sites would fail the same way again and are left out
+func (m *SearchManager) Retry(id string) (*Search, error) {
+	orig, ok := m.Get(id)
+	if !ok {
+		return nil, errSearchNotFound
+	}
+	st := orig.Snapshot()
+	if !st.Finished() {
+		return nil, errSearchRunning
+	}
+
+	p := orig.params
+	p.Sites, p.RetryOf = nil, st.ID
+	for _, f := range st.Failed {
+		if web.FailureKind(f.Kind).Retryable() {
+			p.Sites = append(p.Sites, web.Job{BusinessName: f.Business, URL: f.URL, Query: p.Query})
+		}
+	}
+	if len(p.Sites) == 0 {
+		return nil, errNothingFailed
+	}
+	return m.Start(p), nil
+}
+
+// POST /searches -- enqueue a search and hand back its id
+func (m *SearchManager) CreateSearchHandler(w http.ResponseWriter, r *http.Request) {
+	p, err := parseSearchParams(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{Status: "error", Message: err.Error()})
+		return
+	}
+
+	s := m.Start(p)
+	st := s.Snapshot()
+
+	w.Header().Set("Location", "/searches/"+st.ID)
+	writeJSON(w, http.StatusAccepted, Response{Status: "ok", Data: st})
+}
+
+// POST /searches/{id}/retry -- new search over the failed sites of a finished one
+func (m *SearchManager) RetrySearchHandler(w http.ResponseWriter, r *http.Request) {
+	s, err := m.Retry(chi.URLParam(r, "id"))
+	switch {
+	case errors.Is(err, errSearchNotFound):
+		writeJSON(w, http.StatusNotFound, Response{Status: "error", Message: err.Error()})
+		return
+	case err != nil:
+		writeJSON(w, http.StatusConflict, Response{Status: "error", Message: err.Error()})
+		return
+	}
+	st := s.Snapshot()
+
+	w.Header().Set("Location", "/searches/"+st.ID)
+	writeJSON(w, http.StatusAccepted, Response{Status: "ok", Data: st})
+}
+
+// DELETE /admin/cache -- drop every cached page, the next searches download them again
+func (m *SearchManager) PurgeCacheHandler(w http.ResponseWriter, r *http.Request) {
+	if m.scraper.Cache == nil {
+		writeJSON(w, http.StatusNotFound, Response{Status: "error", Message: "page cache is disabled"})
+		return
+	}
+	purged, err := m.scraper.Cache.Purge()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, Response{Status: "error", Message: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Status: "ok", Data: map[string]int{"purged": purged}})
+}
+
+// GET /searches/{id} -- status, counts and (partial) results
+func (m *SearchManager) GetSearchHandler(w http.ResponseWriter, r *http.Request) {
+	s, ok := m.Get(chi.URLParam(r, "id"))
+	if !ok {
+		writeJSON(w, http.StatusNotFound, Response{Status: "error", Message: "search not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Status: "ok", Data: s.Snapshot()})
+}
+
+// DELETE /searches/{id} -- cancel a queued or running search
+func (m *SearchManager) CancelSearchHandler(w http.ResponseWriter, r *http.Request) {
+	s, ok := m.Cancel(chi.URLParam(r, "id"))
+	if !ok {
+		writeJSON(w, http.StatusNotFound, Response{Status: "error", Message: "search not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Status: "ok", Data: s.Snapshot()})
+}
+
+// GET /searches/{id}/events -- server-sent events for a search, replays from the start (or Last-Event-ID) and ends after completion
+func (m *SearchManager) SearchEventsHandler(w http.ResponseWriter, r *http.Request) {
+	s, ok := m.Get(chi.URLParam(r, "id"))
+	if !ok {
+		writeJSON(w, http.StatusNotFound, Response{Status: "error", Message: "search not found"})
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, Response{Status: "error", Message: "streaming not supported"})
+		return
+	}
+
+	next := 0
+	if last, err := strconv.Atoi(r.Header.Get("Last-Event-ID")); err == nil {
+		next = last
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		evs, changed, finished := s.eventsSince(next)
+		for _, ev := range evs {
+			data, err := json.Marshal(ev)
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.Seq, ev.Type, data)
+			next = ev.Seq
+		}
+		flusher.Flush()
+
+		// the completion event is always the last one
+		if finished {
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// persist hook for the file-based mode
+func saveResultsToFile(p SearchParams, results []utils.JobPageResult, sites []utils.SiteReport) error {
+	if len(sites) > 0 {
+		if err := utils.WriteSiteReports(sites, outputDir); err != nil {
+			return err
+		}
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	return utils.WriteResults(results, outputDir)
+}
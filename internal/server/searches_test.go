@@ -0,0 +1,508 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"cliscraper/internal/backend/web"
+	"cliscraper/internal/utils"
+)
+
+// geocoder that blocks until released, keeps searches in the running state
+type blockingGeocoder struct {
+	release chan struct{}
+	err     error
+}
+
+func (g *blockingGeocoder) Geocode(zip string) (float64, float64, error) {
+	<-g.release
+	return 0, 0, g.err
+}
+
+func newTestSearchRouter(m *SearchManager) http.Handler {
+	r := chi.NewRouter()
+	r.Post("/searches", m.CreateSearchHandler)
+	r.Get("/searches/{id}", m.GetSearchHandler)
+	r.Delete("/searches/{id}", m.CancelSearchHandler)
+	r.Post("/searches/{id}/retry", m.RetrySearchHandler)
+	r.Delete("/admin/cache", m.PurgeCacheHandler)
+	return r
+}
+
+func decodeSearch(t *testing.T, w *httptest.ResponseRecorder) utils.SearchStatus {
+	t.Helper()
+	var resp struct {
+		Status  string             `json:"status"`
+		Message string             `json:"message"`
+		Data    utils.SearchStatus `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	return resp.Data
+}
+
+func waitForSearch(t *testing.T, s *Search) {
+	t.Helper()
+	select {
+	case <-s.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Search did not finish in time")
+	}
+}
+
+func TestCreateSearchHandler(t *testing.T) {
+	geocoder := &blockingGeocoder{release: make(chan struct{})}
+	m := NewSearchManager(geocoder, nil)
+	router := newTestSearchRouter(m)
+
+	req := httptest.NewRequest("POST", "/searches", strings.NewReader("zip=45140&radius=2&title=cook"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status code %d, got %d", http.StatusAccepted, w.Code)
+	}
+
+	st := decodeSearch(t, w)
+	if st.ID == "" {
+		t.Fatal("Expected a search id")
+	}
+	if st.Status != utils.SearchQueued && st.Status != utils.SearchRunning {
+		t.Errorf("Expected queued or running search, got %s", st.Status)
+	}
+	if st.Zip != "45140" || st.Radius != 2 || st.Title != "cook" {
+		t.Errorf("Unexpected search parameters: %+v", st)
+	}
+	if loc := w.Header().Get("Location"); loc != "/searches/"+st.ID {
+		t.Errorf("Expected Location header for %s, got %s", st.ID, loc)
+	}
+
+	// the search is visible while it runs
+	req = httptest.NewRequest("GET", "/searches/"+st.ID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	s, _ := m.Get(st.ID)
+	close(geocoder.release)
+	waitForSearch(t, s)
+}
+
+func TestCreateSearchHandlerInvalidParams(t *testing.T) {
+	m := NewSearchManager(&blockingGeocoder{release: make(chan struct{})}, nil)
+	router := newTestSearchRouter(m)
+
+	tests := []struct {
+		name    string
+		body    string
+		message string
+	}{
+		{name: "Invalid radius", body: "zip=45140&radius=far", message: "invalid radius"},
+		{name: "Invalid zip", body: "zip=abc&radius=2", message: "invalid zip"},
+		{name: "Unlimited per host", body: "zip=45140&radius=2&max_per_host=0", message: "invalid max_per_host"},
+		{name: "Host delay too short", body: "zip=45140&radius=2&host_delay_ms=5", message: "invalid host_delay_ms"},
+		{name: "Rate too high", body: "zip=45140&radius=2&rps=1000", message: "invalid rps"},
+		// looser than the limits all searches share would be accepted and do nothing
+		{name: "Per host above shared cap", body: "zip=45140&radius=2&max_per_host=3", message: "invalid max_per_host, expected 1-2"},
+		{name: "Host delay below shared", body: "zip=45140&radius=2&host_delay_ms=200", message: "invalid host_delay_ms, expected 500-60000"},
+		{name: "Rate above shared", body: "zip=45140&radius=2&rps=25", message: "invalid rps, expected a value above 0 and up to 20"},
+		{name: "Unbalanced title query", body: "zip=45140&radius=2&title=%28cook+OR+chef", message: "invalid title query"},
+		{name: "Unknown profile", body: "zip=45140&radius=2&profile=nope", message: "unknown profile"},
+		{name: "Score above one", body: "zip=45140&radius=2&min_score=1.5", message: "invalid min_score"},
+		{name: "Score not a number", body: "zip=45140&radius=2&min_score=high", message: "invalid min_score"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/searches", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+			}
+			if !strings.Contains(w.Body.String(), tt.message) {
+				t.Errorf("Expected message %q, got %s", tt.message, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestParseSearchParamsLimits(t *testing.T) {
+	req := httptest.NewRequest("GET", "/search?zip=45140&radius=2", nil)
+	p, err := parseSearchParams(req)
+	if err != nil {
+		t.Fatalf("parseSearchParams: %v", err)
+	}
+	if p.Limits != web.DefaultLimits {
+		t.Errorf("Expected default limits, got %+v", p.Limits)
+	}
+
+	req = httptest.NewRequest("GET", "/search?zip=45140&radius=50&max_per_host=1&host_delay_ms=2000&rps=2.5", nil)
+	p, err = parseSearchParams(req)
+	if err != nil {
+		t.Fatalf("parseSearchParams: %v", err)
+	}
+	expected := web.Limits{MaxPerHost: 1, HostInterval: 2 * time.Second, RequestsPerSecond: 2.5}
+	if p.Limits != expected {
+		t.Errorf("Expected limits %+v, got %+v", expected, p.Limits)
+	}
+}
+
+func TestParseSearchParamsTitleQuery(t *testing.T) {
+	req := httptest.NewRequest("GET", `/search?zip=45140&radius=2&title=%22line+cook%22+OR+chef+-sous`, nil)
+	p, err := parseSearchParams(req)
+	if err != nil {
+		t.Fatalf("parseSearchParams: %v", err)
+	}
+	if p.Query == nil || p.Query.String() != `"line cook" OR chef -sous` {
+		t.Errorf("Expected the title to be parsed as a query, got %v", p.Query)
+	}
+
+	req = httptest.NewRequest("GET", "/search?zip=45140&radius=2", nil)
+	if p, err = parseSearchParams(req); err != nil || p.Query != nil {
+		t.Errorf("Expected no query for a blank title, got %v, %v", p.Query, err)
+	}
+}
+
+func TestParseSearchParamsProfile(t *testing.T) {
+	strict, err := web.LoadProfile(strings.NewReader(`{"name": "strict", "context_window": 2}`))
+	if err != nil {
+		t.Fatalf("LoadProfile: %v", err)
+	}
+	saved := detectorProfiles
+	detectorProfiles = web.Profiles{web.DefaultProfileName: web.DefaultProfile(), "strict": strict}
+	defer func() { detectorProfiles = saved }()
+
+	req := httptest.NewRequest("GET", "/search?zip=45140&radius=2&profile=strict", nil)
+	p, err := parseSearchParams(req)
+	if err != nil {
+		t.Fatalf("parseSearchParams: %v", err)
+	}
+	if p.Profile != strict || p.profileName() != "strict" {
+		t.Errorf("Expected the strict profile, got %v", p.profileName())
+	}
+
+	req = httptest.NewRequest("GET", "/search?zip=45140&radius=2", nil)
+	if p, err = parseSearchParams(req); err != nil || p.Profile != nil || p.profileName() != web.DefaultProfileName {
+		t.Errorf("Expected the default profile, got %v, %v", p.Profile, err)
+	}
+}
+
+func TestParseSearchParamsMinScore(t *testing.T) {
+	req := httptest.NewRequest("GET", "/search?zip=45140&radius=2&min_score=0.6", nil)
+	p, err := parseSearchParams(req)
+	if err != nil {
+		t.Fatalf("parseSearchParams: %v", err)
+	}
+	if p.MinScore != 0.6 {
+		t.Errorf("Expected min score 0.6, got %v", p.MinScore)
+	}
+}
+
+func TestCancelSearchHandler(t *testing.T) {
+	geocoder := &blockingGeocoder{release: make(chan struct{})}
+	m := NewSearchManager(geocoder, nil)
+	router := newTestSearchRouter(m)
+
+	s := m.Start(SearchParams{Zip: "45140", Radius: 2})
+	id := s.Snapshot().ID
+
+	req := httptest.NewRequest("DELETE", "/searches/"+id, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	if st := decodeSearch(t, w); st.Status != utils.SearchCancelled {
+		t.Errorf("Expected cancelled search, got %s", st.Status)
+	}
+
+	// a late finishing geocoder must not flip the status back
+	close(geocoder.release)
+	waitForSearch(t, s)
+	if st := s.Snapshot(); st.Status != utils.SearchCancelled || st.FinishedAt == nil {
+		t.Errorf("Expected search to stay cancelled, got %+v", st)
+	}
+}
+
+func TestGetSearchHandlerNotFound(t *testing.T) {
+	router := newTestSearchRouter(NewSearchManager(&blockingGeocoder{}, nil))
+
+	for _, method := range []string{"GET", "DELETE"} {
+		req := httptest.NewRequest(method, "/searches/missing", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s: expected status code %d, got %d", method, http.StatusNotFound, w.Code)
+		}
+	}
+}
+
+func TestSearchFailsWhenGeocodingFails(t *testing.T) {
+	geocoder := &blockingGeocoder{release: make(chan struct{}), err: errors.New("zip lookup down")}
+	close(geocoder.release)
+	m := NewSearchManager(geocoder, nil)
+
+	st := m.Start(SearchParams{Zip: "45140", Radius: 2})
+	waitForSearch(t, st)
+
+	snap := st.Snapshot()
+	if snap.Status != utils.SearchFailed {
+		t.Fatalf("Expected failed search, got %s", snap.Status)
+	}
+	if !strings.Contains(snap.Error, "zip lookup down") {
+		t.Errorf("Expected geocoder error in status, got %q", snap.Error)
+	}
+}
+
+func TestSearchEventsHandler(t *testing.T) {
+	geocoder := &blockingGeocoder{release: make(chan struct{}), err: errors.New("zip lookup down")}
+	m := NewSearchManager(geocoder, nil)
+	router := newTestSearchRouter(m)
+	router.(*chi.Mux).Get("/searches/{id}/events", m.SearchEventsHandler)
+
+	s := m.Start(SearchParams{Zip: "45140", Radius: 2})
+	id := s.Snapshot().ID
+
+	// finish the search while the stream is open
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(geocoder.release)
+	}()
+
+	req := httptest.NewRequest("GET", "/searches/"+id+"/events", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected content type text/event-stream, got %s", ct)
+	}
+
+	body := w.Body.String()
+	if !strings.Contains(body, "event: complete\n") {
+		t.Fatalf("Expected completion event in stream, got %q", body)
+	}
+	if !strings.Contains(body, `"status":"failed"`) || !strings.Contains(body, "zip lookup down") {
+		t.Errorf("Expected failed status and error in completion event, got %q", body)
+	}
+
+	// replaying after the last event id sends nothing new
+	req = httptest.NewRequest("GET", "/searches/"+id+"/events", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if strings.Contains(w.Body.String(), "event:") {
+		t.Errorf("Expected no events after Last-Event-ID, got %q", w.Body.String())
+	}
+}
+
+func TestSearchEventsRecordCounts(t *testing.T) {
+	s := &Search{changed: make(chan struct{}), status: utils.SearchStatus{Status: utils.SearchRunning}}
+
+	s.update(func(st *utils.SearchStatus) { st.Counts.Sites = 3 }, &utils.SearchEvent{Type: utils.EventBusinesses})
+	s.update(func(st *utils.SearchStatus) { st.Counts.Scanned++ }, &utils.SearchEvent{Type: utils.EventScraped})
+	s.finish(utils.SearchDone, "", nil)
+
+	evs, _, finished := s.eventsSince(0)
+	if !finished {
+		t.Error("Expected search to be finished")
+	}
+	if len(evs) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(evs))
+	}
+	for i, ev := range evs {
+		if ev.Seq != i+1 {
+			t.Errorf("Event %d: expected seq %d, got %d", i, i+1, ev.Seq)
+		}
+	}
+	if evs[1].Counts.Scanned != 1 || evs[1].Counts.Sites != 3 {
+		t.Errorf("Expected counts after scrape, got %+v", evs[1].Counts)
+	}
+	if evs[2].Type != utils.EventComplete || evs[2].Status != utils.SearchDone {
+		t.Errorf("Expected completion event last, got %+v", evs[2])
+	}
+}
+
+func TestRetrySearchHandler(t *testing.T) {
+	// every site answers 503, so the retry fails them again and reports why
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer site.Close()
+
+	m := NewSearchManager(&blockingGeocoder{}, nil)
+	m.scraper = web.NewScraper("testbot")
+	m.scraper.Robots = nil
+	router := newTestSearchRouter(m)
+
+	orig, _ := m.register(context.Background(), SearchParams{Zip: "45140", Radius: 2})
+	orig.update(func(st *utils.SearchStatus) {
+		st.Failed = []utils.FailedSite{
+			{Business: "Diner", URL: site.URL + "/", Kind: string(web.FailureTimeout)},
+			{Business: "Bakery", URL: site.URL + "/bakery", Kind: string(web.FailureRobots)},
+		}
+	}, nil)
+
+	req := httptest.NewRequest("POST", "/searches/"+orig.Snapshot().ID+"/retry", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status code %d while the search runs, got %d", http.StatusConflict, w.Code)
+	}
+
+	orig.finish(utils.SearchDone, "", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status code %d, got %d", http.StatusAccepted, w.Code)
+	}
+	st := decodeSearch(t, w)
+	if st.RetryOf != orig.Snapshot().ID {
+		t.Errorf("Expected retry_of %s, got %q", orig.Snapshot().ID, st.RetryOf)
+	}
+
+	retry, _ := m.Get(st.ID)
+	waitForSearch(t, retry)
+	snap := retry.Snapshot()
+	if snap.Counts.Sites != 1 {
+		t.Errorf("Expected only the timed out site to be retried, got %d sites", snap.Counts.Sites)
+	}
+	if snap.Failures[string(web.FailureHTTP5xx)] != 1 || len(snap.Failed) != 1 || snap.Failed[0].Business != "Diner" {
+		t.Errorf("Expected one http_5xx failure for Diner, got %v %+v", snap.Failures, snap.Failed)
+	}
+	// a 503 is a failure, not a dead site
+	if snap.Counts.Flagged != 0 || len(snap.Sites) != 0 {
+		t.Errorf("Expected Diner not flagged, got %d flagged %+v", snap.Counts.Flagged, snap.Sites)
+	}
+
+	// nothing left worth retrying on a search without failures
+	noFailures, _ := m.register(context.Background(), SearchParams{Zip: "45140", Radius: 2})
+	noFailures.finish(utils.SearchDone, "", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/searches/"+noFailures.Snapshot().ID+"/retry", nil))
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status code %d without failed sites, got %d", http.StatusConflict, w.Code)
+	}
+}
+
+func TestSearchCacheStatsAndPurge(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><h1>Open Positions</h1><p>We are hiring a line cook, apply today</p></body></html>`))
+	}))
+	defer site.Close()
+
+	m := NewSearchManager(&blockingGeocoder{}, nil)
+	m.scraper = web.NewScraper("testbot")
+	m.scraper.Robots = nil
+	router := newTestSearchRouter(m)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/admin/cache", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d without a cache, got %d", http.StatusNotFound, w.Code)
+	}
+
+	m.useCache(&web.HTTPCache{Dir: t.TempDir(), TTL: time.Hour})
+	run := func(title string) utils.CacheCounts {
+		t.Helper()
+		s := m.Start(SearchParams{Zip: "45140", Radius: 2, Title: title, Sites: []web.Job{{BusinessName: "Diner", URL: site.URL + "/careers", Titles: []string{title}}}})
+		waitForSearch(t, s)
+		st := s.Snapshot()
+		if st.Counts.Found != 1 {
+			t.Fatalf("Expected the careers page found, got %+v (%s)", st.Counts, st.Error)
+		}
+		return st.Cache
+	}
+	// the same site with another title is answered from disk
+	if first := run("cook"); first != (utils.CacheCounts{Misses: 1}) {
+		t.Errorf("Expected one miss on the first run, got %+v", first)
+	}
+	if second := run("line cook"); second != (utils.CacheCounts{Hits: 1}) {
+		t.Errorf("Expected one hit on the second run, got %+v", second)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/admin/cache", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"purged":1`) {
+		t.Errorf("Expected one purged page, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSearchScrapesSharedSiteOnce(t *testing.T) {
+	var careers atomic.Int32
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/careers" {
+			careers.Add(1)
+		}
+		w.Write([]byte(`<html><body><h1>Open Positions</h1><p>We are hiring a line cook, apply today</p></body></html>`))
+	}))
+	defer site.Close()
+	bakery := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><h1>Open Positions</h1><p>We are hiring a cook, apply today</p></body></html>`))
+	}))
+	defer bakery.Close()
+
+	m := NewSearchManager(&blockingGeocoder{}, nil)
+	m.scraper = web.NewScraper("testbot")
+	m.scraper.Robots = nil
+
+	// two locations of a chain link the same page, one through a tracked link
+	s := m.Start(SearchParams{Zip: "45140", Radius: 2, Title: "cook", Sites: []web.Job{
+		{BusinessName: "Diner Downtown", URL: site.URL + "/careers", Titles: []string{"cook"}},
+		{BusinessName: "Diner Uptown", URL: strings.Replace(site.URL, "http://", "HTTP://", 1) + "/careers?utm_source=maps#jobs", Titles: []string{"cook"}},
+		{BusinessName: "Bakery", URL: bakery.URL + "/careers", Titles: []string{"cook"}},
+	}})
+	waitForSearch(t, s)
+	st := s.Snapshot()
+	if careers.Load() != 1 {
+		t.Errorf("Expected the shared careers page fetched once, got %d", careers.Load())
+	}
+	if st.Counts.Businesses != 3 || st.Counts.Sites != 2 || st.Counts.Deduped != 1 || st.Counts.Scanned != 2 {
+		t.Errorf("Expected 3 businesses on 2 sites with 1 deduped, got %+v", st.Counts)
+	}
+	if st.Counts.Found != 3 || len(st.Results) != 3 {
+		t.Fatalf("Expected a hit for every business, got %d found %+v", st.Counts.Found, st.Results)
+	}
+	names := map[string]bool{}
+	for _, r := range st.Results {
+		names[r.BusinessName] = true
+	}
+	if !names["Diner Downtown"] || !names["Diner Uptown"] || !names["Bakery"] {
+		t.Errorf("Expected the result fanned out to both diners, got %+v", st.Results)
+	}
+}
+
+func TestGroupBySite(t *testing.T) {
+	jobs := []web.Job{
+		{BusinessName: "A", URL: "https://www.riverside.example/"},
+		{BusinessName: "B", URL: "riverside.example?fbclid=x"},
+		{BusinessName: "C", URL: "https://harbor.example"},
+	}
+	sites, businesses := groupBySite(jobs)
+	if len(sites) != 2 || sites[0].URL != "https://www.riverside.example/" || sites[0].BusinessName != "A" {
+		t.Fatalf("Expected 2 sites led by A, got %+v", sites)
+	}
+	if got := businesses[sites[0].URL]; len(got) != 2 || got[1].URL != "riverside.example?fbclid=x" {
+		t.Errorf("Expected A and B behind the first site with their own urls, got %+v", got)
+	}
+	if got := businesses[sites[1].URL]; len(got) != 1 || got[0].BusinessName != "C" {
+		t.Errorf("Expected C alone on the second site, got %+v", got)
+	}
+}
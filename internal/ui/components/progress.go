@@ -1,5 +1,82 @@
-// this is a progress bar implementation, mostly ripped from https://github.com/charmbracelet/bubbletea/tree/main/examples/progress-animated and adjusted for this use case
+// this is a progress bar for the searching state, fed by the search event stream. the bubbletea progress-animated example needs an extra animation dependency, so the bar is drawn with plain lipgloss instead
 
 package components
 
+import (
+	"fmt"
+	"strings"
 
+	"cliscraper/internal/utils"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	defaultBarWidth = 40
+	maxBarWidth     = 60
+)
+
+var (
+	barFullStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff58c4"))
+	barEmptyStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#444444"))
+	barTextStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#81edef"))
+)
+
+type Progress struct {
+	Width  int
+	Counts utils.SearchCounts
+}
+
+// size the bar to the terminal, leaving room for the percentage
+func NewProgress(termWidth int) Progress {
+	width := termWidth - 10
+	if termWidth == 0 {
+		width = defaultBarWidth
+	}
+	if width > maxBarWidth {
+		width = maxBarWidth
+	}
+	if width < 10 {
+		width = 10
+	}
+	return Progress{Width: width}
+}
+
+func (p Progress) SetCounts(c utils.SearchCounts) Progress {
+	p.Counts = c
+	return p
+}
+
+// share of sites scanned, 0 until the businesses are known
+func (p Progress) Percent() float64 {
+	if p.Counts.Sites == 0 {
+		return 0
+	}
+	pct := float64(p.Counts.Scanned) / float64(p.Counts.Sites)
+	if pct > 1 {
+		pct = 1
+	}
+	return pct
+}
+
+func (p Progress) View() string {
+	filled := int(p.Percent() * float64(p.Width))
+	bar := barFullStyle.Render(strings.Repeat("█", filled)) +
+		barEmptyStyle.Render(strings.Repeat("░", p.Width-filled))
+
+	status := "Locating businesses..."
+	if p.Counts.Sites > 0 {
+		status = fmt.Sprintf("%d / %d sites scanned   %d job pages found", p.Counts.Scanned, p.Counts.Sites, p.Counts.Found)
+		if p.Counts.NotHiring > 0 {
+			status += fmt.Sprintf("   %d not hiring", p.Counts.NotHiring)
+		}
+		if p.Counts.Errors > 0 {
+			status += fmt.Sprintf("   %d unreachable", p.Counts.Errors)
+		}
+		if p.Counts.Blocked > 0 {
+			status += fmt.Sprintf("   %d blocked by robots.txt", p.Counts.Blocked)
+		}
+	}
+
+	return fmt.Sprintf("%s %3.0f%%\n%s\n", bar, p.Percent()*100, barTextStyle.Render(status))
+}
@@ -16,19 +16,46 @@ import (
 type JobItem struct {
 	BusinessName string
 	URL          string
+	ATSVendor    string
+	ATSBoard     string
+	// display name sent by the server, the vendor id when missing
+	ATSName      string
+	// postings read from the ATS board, 0 when we only have the page
+	Openings     int
+	// classifier confidence 0-1, 0 when unknown (starred jobs)
+	Score        float64
+	// careers page found but it lists no openings, shown dimmed
+	NotHiring    bool
 	Starred      bool
 }
 
 // implement list.Item
 func (j JobItem) Title() string       { return j.BusinessName }
 func (j JobItem) Description() string { return j.URL }
-func (j JobItem) FilterValue() string { return j.BusinessName }
+// the vendor is part of the filter so typing "greenhouse" narrows the list to those boards
+func (j JobItem) FilterValue() string { return j.BusinessName + " " + j.ATSVendor }
+
+// "Greenhouse · acme", empty for self-hosted pages
+func (j JobItem) ATSLabel() string {
+	if j.ATSVendor == "" {
+		return ""
+	}
+	label := j.ATSName
+	if label == "" {
+		label = j.ATSVendor
+	}
+	if j.ATSBoard != "" {
+		label += " · " + j.ATSBoard
+	}
+	return label
+}
 
 // general styling for list items
 var (
 	selectedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Bold(true)
 	dimStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
 	starStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("227")).Bold(true)
+	atsStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("#81edef"))
 )
 
 type jobDelegate struct{}
@@ -49,6 +76,19 @@ func (d jobDelegate) Render(w io.Writer, m list.Model, index int, listItem list.
 
 	title := marker + item.BusinessName
 	desc := item.URL
+	if label := item.ATSLabel(); label != "" {
+		title += "  " + atsStyle.Render("["+label+"]")
+	}
+	if item.Openings > 0 {
+		desc += fmt.Sprintf("  (%d openings)", item.Openings)
+	}
+	if item.Score > 0 {
+		desc += dimStyle.Render(fmt.Sprintf("  %.0f%% match", item.Score*100))
+	}
+	if item.NotHiring {
+		title += "  " + dimStyle.Render("(not hiring)")
+		desc = dimStyle.Render(item.URL + "  careers page, no openings right now")
+	}
 
 	if index == m.Index() {
 		// highlighting the selected item
@@ -81,10 +121,18 @@ func NewResultsList(results []utils.JobPageResult, width, height int) list.Model
 		)
 	}
 
+	// real hits first, careers pages with nothing open after them
 	items := make([]JobItem, 0, len(results))
+	var closed []JobItem
 	for _, r := range results {
-		items = append(items, JobItem{BusinessName: r.BusinessName, URL: r.URL})
+		item := JobItem{BusinessName: r.BusinessName, URL: r.URL, ATSVendor: r.ATSVendor, ATSBoard: r.ATSBoard, ATSName: r.ATSName, Openings: len(r.Jobs), Score: r.Score, NotHiring: r.NotHiring()}
+		if item.NotHiring {
+			closed = append(closed, item)
+			continue
+		}
+		items = append(items, item)
 	}
+	items = append(items, closed...)
 
 	return newJobList(items, "Job Search Results", width, height, true, true)
 }
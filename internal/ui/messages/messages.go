@@ -12,3 +12,32 @@ type DoneMsg struct {
     Results    []utils.JobPageResult
     Err        error
 }
+
+// backend refused to start the search, e.g. the title query does not parse
+type SearchRejectedMsg struct {
+    Err error
+}
+
+// backend accepted the search, polling can start
+type SearchStartedMsg struct {
+    ID string
+}
+
+// result of polling a running search
+type SearchStatusMsg struct {
+    ID     string
+    Status utils.SearchStatus
+    Err    error
+}
+
+// one event from the search's live stream
+type SearchEventMsg struct {
+    ID    string
+    Event utils.SearchEvent
+}
+
+// the event stream ended, Err is nil when it ended on the completion event
+type SearchStreamClosedMsg struct {
+    ID  string
+    Err error
+}
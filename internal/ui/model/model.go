@@ -3,10 +3,13 @@
 package model
 
 import (
+    "context"
+
     "cliscraper/internal/backend/geo"
     "cliscraper/internal/utils"
     "cliscraper/internal/ui/components"
     "github.com/charmbracelet/bubbles/list"
+    tea "github.com/charmbracelet/bubbletea"
 )
 
 type state int
@@ -31,6 +34,13 @@ type Model struct {
     Err          string
     Businesses   []geo.Business
 
+    // id of the backend search being followed, empty when idle
+    SearchID     string
+    Progress     components.Progress
+    // live events for SearchID, StopStream closes the connection
+    Events       <-chan tea.Msg
+    StopStream   context.CancelFunc
+
     Results      []utils.JobPageResult
     ShowResults  bool
     ResultsList list.Model
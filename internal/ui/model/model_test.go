@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"testing"
 
 	"cliscraper/internal/testutils"
@@ -180,6 +181,22 @@ func (m *mockService) Search(zip, radius, title string) ([]utils.JobPageResult,
 	return testutils.MockJobResults(), nil
 }
 
+func (m *mockService) StartSearch(zip, radius, title string) (string, error) {
+	return "test-search", nil
+}
+
+func (m *mockService) SearchStatus(id string) (*utils.SearchStatus, error) {
+	return &utils.SearchStatus{ID: id, Status: utils.SearchDone, Results: testutils.MockJobResults()}, nil
+}
+
+func (m *mockService) CancelSearch(id string) error {
+	return nil
+}
+
+func (m *mockService) StreamSearch(ctx context.Context, id string, fn func(utils.SearchEvent) error) error {
+	return fn(utils.SearchEvent{Type: utils.EventComplete, Status: utils.SearchDone})
+}
+
 func (m *mockService) Results() ([]utils.JobPageResult, error) {
 	return testutils.MockJobResults(), nil
 }
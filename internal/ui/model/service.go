@@ -2,6 +2,8 @@
 package model
 
 import (
+	"context"
+
 	"cliscraper/internal/utils"
 )
 
@@ -9,6 +11,10 @@ import (
 type Service interface {
 	Health() error
 	Search(zip, radius, title string) ([]utils.JobPageResult, error)
+	StartSearch(zip, radius, title string) (string, error)
+	SearchStatus(id string) (*utils.SearchStatus, error)
+	CancelSearch(id string) error
+	StreamSearch(ctx context.Context, id string, fn func(utils.SearchEvent) error) error
 	Results() ([]utils.JobPageResult, error)
 	Starred() ([]utils.JobPageResult, error)
 }
@@ -5,13 +5,20 @@ import (
 	"cliscraper/internal/ui/model"
 	"cliscraper/internal/ui/messages"
 	"cliscraper/internal/ui/components"
+	"cliscraper/internal/utils"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
+	"context"
+	"errors"
 	"fmt"
+	"time"
 )
 
 type DoneMsg = messages.DoneMsg
 
+// how often the backend is polled when the event stream is unavailable
+const pollInterval = time.Second
+
 // handle incoming messages while in the searching state
 func UpdateSearching(m model.Model, msg tea.Msg) (model.Model, tea.Cmd) {
 	switch msg := msg.(type) {
@@ -20,12 +27,61 @@ func UpdateSearching(m model.Model, msg tea.Msg) (model.Model, tea.Cmd) {
 			m.Spinner, cmd = m.Spinner.Update(msg)
 		return m, cmd
 
+	case messages.SearchRejectedMsg:
+		// the server checks the title query, send the user back to fix it
+		m.Err = msg.Err.Error()
+		m.CurrentState = model.StateTitleInput
+		return m, nil
+
+	case messages.SearchStartedMsg:
+		m.SearchID = msg.ID
+		m.Progress = components.NewProgress(m.Width)
+		m.Events, m.StopStream = streamSearch(m.Service(), msg.ID)
+		return m, WaitForEventCmd(m.Events)
+
+	case messages.SearchEventMsg:
+		// late event for a search we already left behind
+		if msg.ID != m.SearchID {
+			return m, nil
+		}
+		m.Progress = m.Progress.SetCounts(msg.Event.Counts)
+		return m, WaitForEventCmd(m.Events)
+
+	case messages.SearchStreamClosedMsg:
+		if msg.ID != m.SearchID {
+			return m, nil
+		}
+		// grab the final status, if the stream broke early this falls back to polling
+		m.Events = nil
+		return m, fetchSearchCmd(m.Service(), msg.ID, 0)
+
+	case messages.SearchStatusMsg:
+		if msg.ID != m.SearchID {
+			return m, nil
+		}
+		if msg.Err != nil {
+			return UpdateSearching(m, DoneMsg{Err: fmt.Errorf("search failed: %w", msg.Err)})
+		}
+
+		m.Progress = m.Progress.SetCounts(msg.Status.Counts)
+		switch msg.Status.Status {
+		case utils.SearchDone:
+			return UpdateSearching(m, DoneMsg{Results: msg.Status.Results})
+		case utils.SearchFailed:
+			return UpdateSearching(m, DoneMsg{Err: fmt.Errorf("search failed: %s", msg.Status.Error)})
+		case utils.SearchCancelled:
+			return UpdateSearching(m, DoneMsg{Err: fmt.Errorf("search was cancelled")})
+		}
+		return m, PollSearchCmd(m, msg.ID)
+
 	case DoneMsg:
 		if msg.Err != nil {
 			m.Err = msg.Err.Error()
 		} else {
 			m.Results = msg.Results
 		}
+		m = stopStream(m)
+		m.SearchID = ""
 		m.CurrentState = model.StateDone
 		return m, nil
 
@@ -37,26 +93,109 @@ func UpdateSearching(m model.Model, msg tea.Msg) (model.Model, tea.Cmd) {
 
 // render the searching view for the ui
 func ViewSearching(m model.Model) string {
-	return components.StatusStyle.Render(fmt.Sprintf(
+	view := components.StatusStyle.Render(fmt.Sprintf(
 		"%s Searching for %s job pages near %s within radius of %s miles...\n",
 		m.Spinner.View(), m.Title, m.Zip, m.Radius,
 	))
+	if m.SearchID != "" {
+		view += "\n" + m.Progress.View()
+	}
+	return view
 }
 
-// return a tea.Cmd that will run the search asynchronously
+// return a tea.Cmd that will queue the search on the backend, progress then arrives over the event stream
 func StartSearchCmd(m model.Model, zip, radius, title string) tea.Cmd {
     return tea.Batch(
         m.Spinner.Init(), // spinner tick
         func() tea.Msg {
-            results, err := m.Service().Search(zip, radius, title)
+            id, err := m.Service().StartSearch(zip, radius, title)
+            if errors.Is(err, utils.ErrRejected) {
+                return messages.SearchRejectedMsg{Err: err}
+            }
             if err != nil {
                 return DoneMsg{Err: fmt.Errorf("search failed: %w", err)}
             }
-
-            return DoneMsg{
-                Results: results,
-                // if API doesn’t send them, leave Businesses nil.
-            }
+            return messages.SearchStartedMsg{ID: id}
         },
     )
 }
+
+// fetch the status of a running search after a short wait
+func PollSearchCmd(m model.Model, id string) tea.Cmd {
+	return fetchSearchCmd(m.Service(), id, pollInterval)
+}
+
+func fetchSearchCmd(svc model.Service, id string, delay time.Duration) tea.Cmd {
+	fetch := func(time.Time) tea.Msg {
+		status, err := svc.SearchStatus(id)
+		if err != nil {
+			return messages.SearchStatusMsg{ID: id, Err: err}
+		}
+		return messages.SearchStatusMsg{ID: id, Status: *status}
+	}
+	if delay == 0 {
+		return func() tea.Msg { return fetch(time.Now()) }
+	}
+	return tea.Tick(delay, fetch)
+}
+
+// follow the search's event stream in the background, events are handed to the update loop through the channel
+func streamSearch(svc model.Service, id string) (<-chan tea.Msg, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan tea.Msg, 16)
+
+	go func() {
+		defer close(ch)
+		err := svc.StreamSearch(ctx, id, func(ev utils.SearchEvent) error {
+			select {
+			case ch <- messages.SearchEventMsg{ID: id, Event: ev}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		select {
+		case ch <- messages.SearchStreamClosedMsg{ID: id, Err: err}:
+		case <-ctx.Done():
+		}
+	}()
+
+	return ch, cancel
+}
+
+// wait for the next message from the event stream
+func WaitForEventCmd(ch <-chan tea.Msg) tea.Cmd {
+	if ch == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+func stopStream(m model.Model) model.Model {
+	if m.StopStream != nil {
+		m.StopStream()
+	}
+	m.StopStream = nil
+	m.Events = nil
+	return m
+}
+
+// leave a running search: close the stream and tell the backend to stop, failures are ignored since the user already moved on
+func StopSearch(m model.Model) (model.Model, tea.Cmd) {
+	m = stopStream(m)
+	svc, id := m.Service(), m.SearchID
+	m.SearchID = ""
+	if id == "" {
+		return m, nil
+	}
+	return m, func() tea.Msg {
+		_ = svc.CancelSearch(id)
+		return nil
+	}
+}
@@ -34,5 +34,6 @@ func UpdateTitle(m model.Model, msg tea.Msg) (model.Model, tea.Cmd) {
 // render the title input view for the ui
 func ViewTitle(m model.Model) string {
 	return components.LabelStyle.Render("Enter Job Title/Keyword, empty for all openings: ") +
-		components.InputStyle.Render(m.Title) + "\n"
+		components.InputStyle.Render(m.Title) + "\n" +
+		components.FooterStyle.Render(`e.g. "line cook" OR chef -sous`) + "\n"
 }
@@ -12,6 +12,7 @@ import (
 	"cliscraper/internal/ui/messages"
 	//"cliscraper/internal/utils"
 
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -45,9 +46,13 @@ func (u UI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					//do nothing, prevent going back to radius input -- below is going back on q, need for testing starred
 					u.CurrentState = model.PreviousState(u.CurrentState)
 				} else {
+					// leaving a running search, stop it on the backend too
+					if u.CurrentState == model.StateSearching {
+						u.Model, cmd = states.StopSearch(u.Model)
+					}
 					u.CurrentState = model.PreviousState(u.CurrentState)
 					
-			return u, nil
+			return u, cmd
 		}
 		// ctrl+c always quits completely
 		case "ctrl+c":
@@ -116,8 +121,13 @@ func (u UI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		    }
 		}
 
-	case messages.DoneMsg:
+	case messages.DoneMsg, messages.SearchStartedMsg, messages.SearchRejectedMsg, messages.SearchStatusMsg,
+		messages.SearchEventMsg, messages.SearchStreamClosedMsg:
 		u.Model, cmd = states.UpdateSearching(u.Model, msg)
+	case spinner.TickMsg:
+		if u.CurrentState == model.StateSearching {
+			u.Model, cmd = states.UpdateSearching(u.Model, msg)
+		}
 	}
 
 	return u, cmd
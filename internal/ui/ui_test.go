@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"cliscraper/internal/ui/messages"
+	"cliscraper/internal/ui/model"
+	"cliscraper/internal/utils"
+)
+
+// service whose backend turns every search down the way the server answers a malformed query
+type rejectingService struct{}
+
+func (rejectingService) Health() error { return nil }
+func (rejectingService) Search(zip, radius, title string) ([]utils.JobPageResult, error) {
+	return nil, nil
+}
+func (rejectingService) StartSearch(zip, radius, title string) (string, error) {
+	return "", fmt.Errorf("%w: invalid title query: unterminated quote", utils.ErrRejected)
+}
+func (rejectingService) SearchStatus(id string) (*utils.SearchStatus, error) { return nil, nil }
+func (rejectingService) CancelSearch(id string) error                        { return nil }
+func (rejectingService) StreamSearch(ctx context.Context, id string, fn func(utils.SearchEvent) error) error {
+	return nil
+}
+func (rejectingService) Results() ([]utils.JobPageResult, error) { return nil, nil }
+func (rejectingService) Starred() ([]utils.JobPageResult, error) { return nil, nil }
+
+// run cmd and any batch inside it, returning the messages they produce
+func runCmd(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		var out []tea.Msg
+		for _, c := range msg {
+			out = append(out, runCmd(c)...)
+		}
+		return out
+	case nil:
+		return nil
+	default:
+		return []tea.Msg{msg}
+	}
+}
+
+func TestRejectedSearchReturnsToTitle(t *testing.T) {
+	u := UI{Model: model.InitialModel(rejectingService{})}
+	u.CurrentState = model.StateTitleInput
+	u.Zip, u.Radius, u.Title = "45140", "5", `"line cook`
+
+	next, cmd := u.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	u = next.(UI)
+	if u.CurrentState != model.StateSearching {
+		t.Fatalf("Expected the search to be submitted, state is %v", u.CurrentState)
+	}
+
+	rejected := false
+	for _, msg := range runCmd(cmd) {
+		if _, ok := msg.(messages.SearchRejectedMsg); ok {
+			rejected = true
+		}
+		next, _ = u.Update(msg)
+		u = next.(UI)
+	}
+	if !rejected {
+		t.Fatal("Expected the 400 to come back as a rejection")
+	}
+	if u.CurrentState != model.StateTitleInput || u.SearchID != "" {
+		t.Errorf("Expected the title prompt again, state %v search %q", u.CurrentState, u.SearchID)
+	}
+	if view := u.View(); !strings.Contains(view, "unterminated quote") {
+		t.Errorf("Expected the server's message on screen, got %q", view)
+	}
+}
@@ -2,7 +2,8 @@
 package utils
 
 import (
-
+	"net/url"
+	"strings"
 )
 
 func NormalizeURL(url string) string {
@@ -16,6 +17,63 @@ func NormalizeURL(url string) string {
 	return url
 }
 
+// query parameters that only say where a visitor came from
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "msclkid": true, "yclid": true, "igshid": true,
+	"mc_cid": true, "mc_eid": true, "_ga": true, "_gl": true, "ref_src": true,
+}
+
+// one spelling per page: lowercase scheme and host, http:// when the scheme is missing, no default port, no fragment,
+// no tracking parameters, the rest of the query sorted. www. and the path's case are kept, sites do not always answer without them.
+// urls that do not parse come back trimmed but otherwise untouched
+func CanonicalURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if port := u.Port(); port != "" && !(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	u.Host = host
+	u.Fragment, u.RawFragment = "", ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	query := u.Query()
+	for key := range query {
+		if k := strings.ToLower(key); strings.HasPrefix(k, "utm_") || trackingParams[k] {
+			query.Del(key)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// the site a url belongs to, for grouping businesses that share one: the canonical url without scheme, www. or trailing slash.
+// chain locations pointing at https://www.brand.com/ and http://brand.com share a key, brand.com/dayton keeps its own
+func SiteKey(raw string) string {
+	canonical := CanonicalURL(raw)
+	if i := strings.Index(canonical, "://"); i >= 0 {
+		canonical = canonical[i+3:]
+	}
+	canonical = strings.TrimPrefix(canonical, "www.")
+	if path, query, found := strings.Cut(canonical, "?"); found {
+		return strings.TrimSuffix(path, "/") + "?" + query
+	}
+	return strings.TrimSuffix(canonical, "/")
+}
+
 func IsValidZip(zip string) bool {
     if len(zip) != 5 {
         return false
@@ -47,6 +47,44 @@ func TestNormalizeURL(t *testing.T) {
 	}
 }
 
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "Already canonical", input: "https://brand.com/", expected: "https://brand.com/"},
+		{name: "Case and default port", input: "HTTPS://WWW.Brand.COM:443/Careers", expected: "https://www.brand.com/Careers"},
+		{name: "Other port kept", input: "http://brand.com:8080", expected: "http://brand.com:8080/"},
+		{name: "Missing scheme", input: "www.brand.com", expected: "http://www.brand.com/"},
+		{name: "Tracking params", input: "https://brand.com/?utm_source=osm&UTM_Campaign=x&fbclid=abc&store=12#hours", expected: "https://brand.com/?store=12"},
+		{name: "Query sorted", input: "https://brand.com/page?b=2&a=1", expected: "https://brand.com/page?a=1&b=2"},
+		{name: "Empty", input: "  ", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := CanonicalURL(tt.input); result != tt.expected {
+				t.Errorf("CanonicalURL(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSiteKey(t *testing.T) {
+	same := []string{"https://www.brand.com/", "http://brand.com", "HTTP://Brand.com:80/?utm_source=osm", "brand.com/#menu"}
+	for _, u := range same {
+		if key := SiteKey(u); key != "brand.com" {
+			t.Errorf("SiteKey(%q) = %q, want brand.com", u, key)
+		}
+	}
+	for _, u := range []string{"https://brand.com/dayton", "https://brand.com/?store=12", "https://shop.brand.com/"} {
+		if key := SiteKey(u); key == "brand.com" {
+			t.Errorf("SiteKey(%q) should not group with brand.com", u)
+		}
+	}
+}
+
 func TestIsValidZip(t *testing.T) {
 	tests := []struct {
 		name     string
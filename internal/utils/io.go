@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"sort"
 	"os"
+	"strings"
 	"time"
 
 	"cliscraper/internal/database"
@@ -20,6 +21,46 @@ type JobPageResult struct {
 	BusinessName string `json:"business_name"`
 	URL	   string `json:"url"`
 	Description string `json:"description"`
+	// applicant tracking system behind the page, e.g. greenhouse / acme
+	ATSVendor string `json:"ats_vendor,omitempty"`
+	ATSBoard  string `json:"ats_board,omitempty"`
+	// the vendor's display name, e.g. Greenhouse, so clients don't need the vendor table
+	ATSName string `json:"ats_name,omitempty"`
+	// individual openings from the ATS board api, empty for pages we could only link to
+	Jobs []database.Job `json:"jobs,omitempty"`
+	// 0-1 confidence that URL is a careers page, with the signals that fired
+	Score   float64  `json:"score"`
+	Reasons []string `json:"reasons,omitempty"`
+	// database.JobStatusNotHiring when URL is a careers page saying there are no openings
+	Status string `json:"status,omitempty"`
+	// linked pdfs that look like job descriptions or printable applications
+	Documents []string `json:"documents,omitempty"`
+	// where the business website redirected to, and the urls on the way
+	FinalURL  string   `json:"final_url,omitempty"`
+	Redirects []string `json:"redirects,omitempty"`
+}
+
+// the careers page exists but lists nothing open
+func (r JobPageResult) NotHiring() bool {
+	return r.Status == database.JobStatusNotHiring
+}
+
+// keep results from one ATS vendor, "any" keeps every ATS-backed result and "none" the self-hosted ones
+func FilterByATS(results []JobPageResult, vendor string) []JobPageResult {
+	vendor = strings.ToLower(strings.TrimSpace(vendor))
+	if vendor == "" {
+		return results
+	}
+	filtered := make([]JobPageResult, 0, len(results))
+	for _, r := range results {
+		switch {
+		case vendor == "any" && r.ATSVendor != "",
+			vendor == "none" && r.ATSVendor == "",
+			vendor == r.ATSVendor:
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
 }
 
 type DatabaseManager struct {
@@ -28,6 +69,7 @@ type DatabaseManager struct {
 	businessRepo *database.BusinessRepository
 	jobResultRepo *database.JobResultRepository
 	geoResultRepo *database.GeoResultRepository
+	domainRepo    *database.DomainRepository
 }
 
 func NewDatabaseManager() (*DatabaseManager, error) {
@@ -44,9 +86,15 @@ func NewDatabaseManager() (*DatabaseManager, error) {
 		businessRepo:  database.NewBusinessRepository(repo),
 		jobResultRepo: database.NewJobResultRepository(repo),
 		geoResultRepo: database.NewGeoResultRepository(repo),
+		domainRepo:    database.NewDomainRepository(repo),
 	}, nil
 }
 
+// per-domain careers pages remembered between searches
+func (dm *DatabaseManager) Domains() *database.DomainRepository {
+	return dm.domainRepo
+}
+
 func (dm *DatabaseManager) Close() error {
 	return dm.client.Close()
 }
@@ -103,21 +151,43 @@ func (dm *DatabaseManager) LoadLatestResultsFromDB(userID primitive.ObjectID) ([
 		businessMap[business.ID] = business
 	}
 
+	return groupJobs(jobs, businessMap), nil
+}
+
+// one result per business, postings are grouped back under the page they were found on
+func groupJobs(jobs []database.Job, businessMap map[primitive.ObjectID]database.Business) []JobPageResult {
 	results := make([]JobPageResult, 0, len(jobs))
+	resultIndex := make(map[primitive.ObjectID]int)
 	for _, job := range jobs {
 		business, exists := businessMap[job.BusinessID]
 		if !exists {
 			continue
 		}
 
-		results = append(results, JobPageResult{
-			BusinessName: business.Name,
-			URL:          job.URL,
-			Description:  job.Description,
-		})
+		i, seen := resultIndex[business.ID]
+		if !seen {
+			i = len(results)
+			resultIndex[business.ID] = i
+			results = append(results, JobPageResult{
+				BusinessName: business.Name,
+				URL:          business.URL,
+				ATSVendor:    job.ATSVendor,
+				ATSBoard:     job.ATSBoard,
+				Score:        job.Score,
+				Reasons:      job.Reasons,
+				Status:       job.Status,
+				FinalURL:     business.FinalURL,
+				Redirects:    business.Redirects,
+			})
+		}
+		// a json-ld posting without its own url carries the page's, only the flag tells the page record apart
+		if job.Page {
+			results[i].Description = job.Description
+		} else {
+			results[i].Jobs = append(results[i].Jobs, job)
+		}
 	}
-
-	return results, nil
+	return results
 }
 
 // legacy function
@@ -153,6 +223,8 @@ func (dm *DatabaseManager) WriteResultsToDB(userID primitive.ObjectID, queryTitl
 
 	businessMap := make(map[string]database.Business)
 	businessCounter := 0
+	// business name for each entry in jobs, a result can carry several postings
+	jobOwners := make([]string, 0, len(results))
 
 	for _, result := range results {
 		businessKey := result.BusinessName + "|" + result.URL
@@ -164,20 +236,18 @@ func (dm *DatabaseManager) WriteResultsToDB(userID primitive.ObjectID, queryTitl
 				Address: "Address not available", // placeholder address
 				Lat:     0,  // coordinates will be set from geo data if available
 				Lon:     0,
+				FinalURL:  result.FinalURL,
+				Redirects: result.Redirects,
 			}
 			businessMap[businessKey] = business
 			businesses = append(businesses, business)
 			businessCounter++
 		}
 
-		// Create job
-		job := database.Job{
-			Title:       queryTitle, // query title as job title
-			Description: result.Description,
-			URL:         result.URL,
-			PostedAt:    &[]time.Time{time.Now()}[0],
+		for _, job := range resultJobs(queryTitle, result) {
+			jobs = append(jobs, job)
+			jobOwners = append(jobOwners, result.BusinessName)
 		}
-		jobs = append(jobs, job)
 	}
 
 	// save businesses to database
@@ -193,14 +263,14 @@ func (dm *DatabaseManager) WriteResultsToDB(userID primitive.ObjectID, queryTitl
 	for i, job := range jobs {
 		found := false
 		for j, business := range businesses {
-			if business.Name == results[i].BusinessName {
+			if business.Name == jobOwners[i] {
 				job.BusinessID = businessIDs[j]
 				found = true
 				break
 			}
 		}
 		if !found {
-			fmt.Printf("Warning: No business found for job result: %s\n", results[i].BusinessName)
+			fmt.Printf("Warning: No business found for job result: %s\n", jobOwners[i])
 		}
 		jobs[i] = job
 	}
@@ -222,6 +292,73 @@ func (dm *DatabaseManager) WriteResultsToDB(userID primitive.ObjectID, queryTitl
 	return nil
 } 
 
+// the jobs saved for a result: postings from an ATS board or page markup one by one, otherwise the page itself
+func resultJobs(queryTitle string, result JobPageResult) []database.Job {
+	if len(result.Jobs) > 0 {
+		jobs := make([]database.Job, 0, len(result.Jobs))
+		for _, posting := range result.Jobs {
+			if posting.PostedAt == nil {
+				posting.PostedAt = &[]time.Time{time.Now()}[0]
+			}
+			posting.Score, posting.Reasons = result.Score, result.Reasons
+			jobs = append(jobs, posting)
+		}
+		return jobs
+	}
+	return []database.Job{{
+		Title:       queryTitle, // query title as job title
+		Description: result.Description,
+		URL:         result.URL,
+		ATSVendor:   result.ATSVendor,
+		ATSBoard:    result.ATSBoard,
+		Score:       result.Score,
+		Reasons:     result.Reasons,
+		Status:      result.Status,
+		Page:        true,
+		PostedAt:    &[]time.Time{time.Now()}[0],
+	}}
+}
+
+// sites with a broken web presence, written next to results.json
+func WriteSiteReports(sites []SiteReport, outDir string) error {
+	if err := os.MkdirAll(outDir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	data, err := json.MarshalIndent(sites, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode site reports: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(outDir, "sites.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write site reports: %w", err)
+	}
+	return nil
+}
+
+// save the businesses behind flagged sites, so broken web presence can be reported from mongo
+func (dm *DatabaseManager) WriteSiteReportsToDB(sites []SiteReport) error {
+	businesses := make([]database.Business, 0, len(sites))
+	for _, site := range sites {
+		if site.Flag == "" {
+			continue
+		}
+		businesses = append(businesses, database.Business{
+			Name:      site.Business,
+			URL:       site.URL,
+			Address:   "Address not available",
+			FinalURL:  site.FinalURL,
+			Redirects: site.Redirects,
+			SiteFlag:  site.Flag,
+		})
+	}
+	if len(businesses) == 0 {
+		return nil
+	}
+	if _, err := dm.businessRepo.SaveBusinesses(businesses); err != nil {
+		return fmt.Errorf("failed to save flagged businesses: %w", err)
+	}
+	return nil
+}
+
 func WriteGeoResults(data []byte, outDir string) error {
 	if err := os.MkdirAll(outDir, os.ModePerm); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
@@ -4,6 +4,10 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"cliscraper/internal/database"
 )
 
 func TestWriteResults(t *testing.T) {
@@ -130,4 +134,59 @@ func TestWriteResultsCreatesDirectory(t *testing.T) {
 	if _, err := os.Stat(subDir); os.IsNotExist(err) {
 		t.Fatalf("Directory was not created: %s", subDir)
 	}
-}
\ No newline at end of file
+}
+
+func TestFilterByATS(t *testing.T) {
+	results := []JobPageResult{
+		{BusinessName: "Self Hosted", URL: "https://a.example.com/careers"},
+		{BusinessName: "Greenhouse Co", URL: "https://boards.greenhouse.io/gh", ATSVendor: "greenhouse", ATSBoard: "gh"},
+		{BusinessName: "Lever Co", URL: "https://jobs.lever.co/lv", ATSVendor: "lever", ATSBoard: "lv"},
+	}
+
+	tests := []struct {
+		vendor   string
+		expected int
+	}{
+		{vendor: "", expected: 3},
+		{vendor: "Greenhouse", expected: 1},
+		{vendor: "any", expected: 2},
+		{vendor: "none", expected: 1},
+		{vendor: "workday", expected: 0},
+	}
+	for _, tt := range tests {
+		if got := FilterByATS(results, tt.vendor); len(got) != tt.expected {
+			t.Errorf("FilterByATS(%q): expected %d results, got %d", tt.vendor, tt.expected, len(got))
+		}
+	}
+}
+
+func TestResultJobsRoundTrip(t *testing.T) {
+	pageURL := "https://riverside.example/careers"
+	results := []JobPageResult{
+		// json-ld posting without its own url, it carries the page's
+		{BusinessName: "Riverside", URL: pageURL, Jobs: []database.Job{{Title: "Line Cook", URL: pageURL}}},
+		{BusinessName: "Harbor", URL: "https://harbor.example/jobs", Description: "Now hiring servers"},
+	}
+
+	var jobs []database.Job
+	businessMap := make(map[primitive.ObjectID]database.Business)
+	for _, r := range results {
+		id := primitive.NewObjectID()
+		businessMap[id] = database.Business{ID: id, Name: r.BusinessName, URL: r.URL}
+		for _, job := range resultJobs("cook", r) {
+			job.BusinessID = id
+			jobs = append(jobs, job)
+		}
+	}
+
+	loaded := groupJobs(jobs, businessMap)
+	if len(loaded) != 2 {
+		t.Fatalf("Expected 2 results back, got %+v", loaded)
+	}
+	if len(loaded[0].Jobs) != 1 || loaded[0].Jobs[0].Title != "Line Cook" || loaded[0].Description != "" {
+		t.Errorf("Expected the posting kept under Riverside, got %+v", loaded[0])
+	}
+	if len(loaded[1].Jobs) != 0 || loaded[1].Description != "Now hiring servers" {
+		t.Errorf("Expected Harbor's page back as its description, got %+v", loaded[1])
+	}
+}
@@ -0,0 +1,135 @@
+// shared types describing an asynchronous search, used by both the server and the api client
+package utils
+
+import (
+	"errors"
+	"time"
+)
+
+// the server turned a search request down (400) before starting it, e.g. for a malformed title query.
+// the api client wraps it around the server's message
+var ErrRejected = errors.New("search rejected")
+
+// search lifecycle
+const (
+	SearchQueued    = "queued"
+	SearchRunning   = "running"
+	SearchDone      = "done"
+	SearchFailed    = "failed"
+	SearchCancelled = "cancelled"
+)
+
+// running tallies for a search, updated as the worker pool reports back
+type SearchCounts struct {
+	Businesses int `json:"businesses"`
+	Sites      int `json:"sites"`
+	// businesses sharing a website with an earlier one, their site is scraped once and the result reported for each
+	Deduped   int `json:"deduped"`
+	Scanned   int `json:"scanned"`
+	Found     int `json:"found"`
+	NotHiring int `json:"not_hiring"`
+	Errors    int `json:"errors"`
+	Cancelled int `json:"cancelled"`
+	Blocked   int `json:"robots_blocked"`
+	// parked, social-only or dead websites
+	Flagged int `json:"flagged"`
+	// sites where careers paths were probed after the crawl found nothing, how many of those the probes found, and the ratio
+	Probed       int     `json:"probed"`
+	ProbeHits    int     `json:"probe_hits"`
+	ProbeHitRate float64 `json:"probe_hit_rate"`
+	// sites answered from the careers page remembered for their domain, without a crawl
+	Remembered int `json:"remembered"`
+}
+
+// count a scraped site's probes, hit is whether they found its job page
+func (c *SearchCounts) AddProbe(hit bool) {
+	c.Probed++
+	if hit {
+		c.ProbeHits++
+	}
+	c.ProbeHitRate = float64(c.ProbeHits) / float64(c.Probed)
+}
+
+// state of a search as returned by GET /searches/{id}, results are partial until the search is done
+type SearchStatus struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Zip    string `json:"zip"`
+	Radius int    `json:"radius"`
+	Title  string `json:"title"`
+	// detector profile the search runs with
+	Profile string          `json:"profile,omitempty"`
+	Message string          `json:"message,omitempty"`
+	Error   string          `json:"error,omitempty"`
+	Counts  SearchCounts    `json:"counts"`
+	Results []JobPageResult `json:"results"`
+	// failed and robots-blocked sites by failure kind ("dns", "timeout", "http_5xx"...), and the sites themselves
+	Failures map[string]int `json:"failures,omitempty"`
+	Failed   []FailedSite   `json:"failed,omitempty"`
+	// websites that redirected elsewhere or are parked, social-only or dead
+	Sites []SiteReport `json:"sites,omitempty"`
+	// page cache use while scraping, zero when the cache is off
+	Cache CacheCounts `json:"cache,omitzero"`
+	// id of the search whose failed sites this one re-runs
+	RetryOf    string     `json:"retry_of,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// pages served from the on-disk cache as is, after a 304, and downloaded
+type CacheCounts struct {
+	Hits        int `json:"hits"`
+	Revalidated int `json:"revalidated"`
+	Misses      int `json:"misses"`
+}
+
+// a site that could not be scraped
+type FailedSite struct {
+	Business string `json:"business"`
+	URL      string `json:"url"`
+	Kind     string `json:"kind"`
+	Error    string `json:"error,omitempty"`
+}
+
+// where a business website led, Flag is a database.Site* value for a broken web presence
+type SiteReport struct {
+	Business  string   `json:"business"`
+	URL       string   `json:"url"`
+	FinalURL  string   `json:"final_url,omitempty"`
+	Redirects []string `json:"redirects,omitempty"`
+	Flag      string   `json:"flag,omitempty"`
+}
+
+// true once a search can no longer change
+func (s SearchStatus) Finished() bool {
+	switch s.Status {
+	case SearchDone, SearchFailed, SearchCancelled:
+		return true
+	}
+	return false
+}
+
+// event types streamed from GET /searches/{id}/events
+const (
+	EventGeocoded   = "geocoded"
+	EventBusinesses = "businesses"
+	EventScraped    = "scraped"
+	EventHit        = "hit"
+	EventComplete   = "complete"
+)
+
+// one step of a running search, counts are the totals after the step
+type SearchEvent struct {
+	Seq      int            `json:"seq"`
+	Type     string         `json:"type"`
+	Counts   SearchCounts   `json:"counts"`
+	Lat      float64        `json:"lat,omitempty"`
+	Lon      float64        `json:"lon,omitempty"`
+	Business string         `json:"business,omitempty"`
+	URL      string         `json:"url,omitempty"`
+	Result   *JobPageResult `json:"result,omitempty"`
+	Status   string         `json:"status,omitempty"`
+	Error    string         `json:"error,omitempty"`
+	// failure kind of a scraped site that failed
+	Failure string `json:"failure,omitempty"`
+}